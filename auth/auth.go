@@ -0,0 +1,130 @@
+// Package auth provides the credential sources used to authorize gogoo managers
+package auth
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+
+	"golang.org/x/net/context"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"golang.org/x/oauth2/jwt"
+)
+
+// Provider supplies the token source shared by all managers of a GoGoo.
+// A nil token source means requests are sent without authorization.
+type Provider interface {
+	TokenSource(ctx context.Context, scopes ...string) (oauth2.TokenSource, error)
+}
+
+// ProviderFunc adapts an ordinary function to Provider
+type ProviderFunc func(ctx context.Context, scopes ...string) (oauth2.TokenSource, error)
+
+func (f ProviderFunc) TokenSource(ctx context.Context, scopes ...string) (oauth2.TokenSource, error) {
+	return f(ctx, scopes...)
+}
+
+// PemKey authorizes by the service account and its PEM encoded private key
+// https://cloud.google.com/storage/docs/authentication#converting-the-private-key
+func PemKey(serviceAccount string, key []byte) Provider {
+	return ProviderFunc(func(ctx context.Context, scopes ...string) (oauth2.TokenSource, error) {
+		if serviceAccount == "" {
+			return nil, fmt.Errorf("service account is empty")
+		}
+		if err := checkPrivateKey(key); err != nil {
+			return nil, err
+		}
+
+		conf := &jwt.Config{
+			Email:      serviceAccount,
+			PrivateKey: key,
+			Scopes:     scopes,
+			TokenURL:   google.JWTTokenURL,
+		}
+
+		return conf.TokenSource(ctx), nil
+	})
+}
+
+// JSONKey authorizes by the JSON key file content downloaded from developer console
+func JSONKey(jsonKey []byte) Provider {
+	return ProviderFunc(func(ctx context.Context, scopes ...string) (oauth2.TokenSource, error) {
+		conf, err := google.JWTConfigFromJSON(jsonKey, scopes...)
+		if err != nil {
+			return nil, err
+		}
+
+		return conf.TokenSource(ctx), nil
+	})
+}
+
+// JSONKeyFile authorizes by the JSON key file located at path
+func JSONKeyFile(path string) Provider {
+	return ProviderFunc(func(ctx context.Context, scopes ...string) (oauth2.TokenSource, error) {
+		jsonKey, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		return JSONKey(jsonKey).TokenSource(ctx, scopes...)
+	})
+}
+
+// ApplicationDefault authorizes by the Application Default Credentials
+// https://developers.google.com/identity/protocols/application-default-credentials
+func ApplicationDefault() Provider {
+	return ProviderFunc(func(ctx context.Context, scopes ...string) (oauth2.TokenSource, error) {
+		return google.DefaultTokenSource(ctx, scopes...)
+	})
+}
+
+// ComputeMetadata authorizes by the service account attached to the running GCE VM.
+// Scopes are decided when the VM is created, the requested scopes are ignored.
+// An empty account means the default service account.
+func ComputeMetadata(account string) Provider {
+	return ProviderFunc(func(ctx context.Context, scopes ...string) (oauth2.TokenSource, error) {
+		return google.ComputeTokenSource(account), nil
+	})
+}
+
+// StaticTokenSource authorizes by the given token source as it is
+func StaticTokenSource(ts oauth2.TokenSource) Provider {
+	return ProviderFunc(func(ctx context.Context, scopes ...string) (oauth2.TokenSource, error) {
+		if ts == nil {
+			return nil, fmt.Errorf("token source is nil")
+		}
+
+		return ts, nil
+	})
+}
+
+// None sends requests without authorization, it is for emulators and local fakes
+func None() Provider {
+	return ProviderFunc(func(ctx context.Context, scopes ...string) (oauth2.TokenSource, error) {
+		return nil, nil
+	})
+}
+
+// checkPrivateKey checks the key is a PEM encoded RSA private key, the format jwt.Config expects
+func checkPrivateKey(key []byte) error {
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return fmt.Errorf("private key is not PEM encoded")
+	}
+
+	if parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		// The JWT is signed by RS256 only
+		if _, ok := parsed.(*rsa.PrivateKey); !ok {
+			return fmt.Errorf("private key should be RSA, got %T", parsed)
+		}
+		return nil
+	}
+	if _, err := x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+		return fmt.Errorf("private key should be a PEM or plain PKCS1 or PKCS8: %s", err.Error())
+	}
+
+	return nil
+}
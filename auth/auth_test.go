@@ -0,0 +1,111 @@
+package auth_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/browny/gogoo/auth"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"golang.org/x/oauth2"
+)
+
+func testedPemKey() []byte {
+	privateKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	})
+}
+
+func TestPemKey(t *testing.T) {
+	ts, err := auth.PemKey("ooxx@developer.gserviceaccount.com", testedPemKey()).
+		TokenSource(context.Background(), "scope")
+	assert.Nil(t, err)
+	assert.NotNil(t, ts)
+
+	_, err = auth.PemKey("ooxx@developer.gserviceaccount.com", []byte("bad key")).
+		TokenSource(context.Background(), "scope")
+	assert.NotNil(t, err)
+
+	_, err = auth.PemKey("", testedPemKey()).TokenSource(context.Background(), "scope")
+	assert.NotNil(t, err)
+
+	// PKCS8 keys are accepted only for RSA
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(rsaKey)
+	_, err = auth.PemKey("ooxx@developer.gserviceaccount.com",
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})).
+		TokenSource(context.Background(), "scope")
+	assert.Nil(t, err)
+
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	pkcs8, _ = x509.MarshalPKCS8PrivateKey(ecKey)
+	_, err = auth.PemKey("ooxx@developer.gserviceaccount.com",
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})).
+		TokenSource(context.Background(), "scope")
+	assert.NotNil(t, err)
+}
+
+func TestJSONKey(t *testing.T) {
+	jsonKey := []byte(`{
+		"type": "service_account",
+		"client_email": "ooxx@developer.gserviceaccount.com",
+		"private_key": "key"
+	}`)
+
+	ts, err := auth.JSONKey(jsonKey).TokenSource(context.Background(), "scope")
+	assert.Nil(t, err)
+	assert.NotNil(t, ts)
+
+	_, err = auth.JSONKey([]byte("{")).TokenSource(context.Background(), "scope")
+	assert.NotNil(t, err)
+
+	file, _ := ioutil.TempFile("", "key")
+	defer os.Remove(file.Name())
+	file.Write(jsonKey)
+	file.Close()
+
+	ts, err = auth.JSONKeyFile(file.Name()).TokenSource(context.Background(), "scope")
+	assert.Nil(t, err)
+	assert.NotNil(t, ts)
+
+	_, err = auth.JSONKeyFile(file.Name()+"-not-existed").TokenSource(context.Background(), "scope")
+	assert.NotNil(t, err)
+}
+
+func TestStaticTokenSource(t *testing.T) {
+	src := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"})
+
+	ts, err := auth.StaticTokenSource(src).TokenSource(context.Background(), "scope")
+	assert.Nil(t, err)
+
+	token, _ := ts.Token()
+	assert.Equal(t, "token", token.AccessToken)
+
+	_, err = auth.StaticTokenSource(nil).TokenSource(context.Background(), "scope")
+	assert.NotNil(t, err)
+}
+
+func TestComputeMetadata(t *testing.T) {
+	ts, err := auth.ComputeMetadata("").TokenSource(context.Background(), "scope")
+
+	assert.Nil(t, err)
+	assert.NotNil(t, ts)
+}
+
+func TestNone(t *testing.T) {
+	ts, err := auth.None().TokenSource(context.Background(), "scope")
+
+	assert.Nil(t, err)
+	assert.Nil(t, ts)
+}
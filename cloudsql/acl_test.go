@@ -0,0 +1,36 @@
+package cloudsql_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/browny/gogoo/gogootest"
+
+	"github.com/stretchr/testify/assert"
+	sql "google.golang.org/api/sqladmin/v1beta4"
+)
+
+func TestAclEntries(t *testing.T) {
+	server, g := gogootest.NewGoGoo()
+	defer server.Close()
+
+	server.AddDatabase(&sql.DatabaseInstance{
+		Name: "db-test",
+		Settings: &sql.Settings{IpConfiguration: &sql.IpConfiguration{
+			AuthorizedNetworks: []*sql.AclEntry{&sql.AclEntry{Name: "office", Value: "1.2.3.4"}},
+		}},
+	})
+
+	_, err := g.PatchAclEntriesOfDatabase(gogootest.ProjectId, "db-test", []*sql.AclEntry{
+		&sql.AclEntry{Name: "office", Value: "1.2.3.4"},
+		&sql.AclEntry{Name: "vm-1", Value: "5.6.7.8"},
+	})
+	assert.Nil(t, err)
+
+	entries, err := g.GetFilteredAclEntriesOfDatabase(gogootest.ProjectId, "db-test", func(name string) bool {
+		return strings.HasPrefix(name, "vm-")
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "5.6.7.8", entries[0].Value)
+}
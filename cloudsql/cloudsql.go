@@ -2,33 +2,56 @@
 package cloudsql
 
 import (
-	"fmt"
+	"net/http"
+
+	"github.com/browny/gogoo/auth"
+	"github.com/browny/gogoo/gerror"
+	"github.com/browny/gogoo/retry"
 
 	log "github.com/cihub/seelog"
+	"golang.org/x/net/context"
 	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
-	"golang.org/x/oauth2/jwt"
 	sql "google.golang.org/api/sqladmin/v1beta4"
 )
 
+// Scopes lists the oauth2 scopes required by CloudSqlManager
+var Scopes = []string{
+	sql.SqlserviceAdminScope,
+}
+
 // BuildCloudSqlService builds the singlton service for CloudSql
 func BuildCloudSqlService(serviceEmail string, key []byte) (*sql.Service, error) {
-	conf := &jwt.Config{
-		Email:      serviceEmail,
-		PrivateKey: key,
-		Scopes: []string{
-			sql.SqlserviceAdminScope,
-		},
-		TokenURL: google.JWTTokenURL,
+	ts, err := auth.PemKey(serviceEmail, key).TokenSource(oauth2.NoContext, Scopes...)
+	if err != nil {
+		return nil, err
 	}
 
-	if service, err := sql.New(conf.Client(oauth2.NoContext)); err != nil {
+	return BuildCloudSqlServiceWithClient(oauth2.NewClient(oauth2.NoContext, ts))
+}
+
+// BuildCloudSqlServiceWithClient builds the service for CloudSql upon an authorized http client
+func BuildCloudSqlServiceWithClient(client *http.Client) (*sql.Service, error) {
+	if service, err := sql.New(client); err != nil {
 		return nil, err
 	} else {
 		return service, nil
 	}
 }
 
+//go:generate mockery -name=Admin
+
+// Admin is the method set of CloudSqlManager, it is for consumers to mock CloudSqlManager.
+type Admin interface {
+	GetDatabase(projectId, dbName string) (*sql.DatabaseInstance, error)
+	GetDatabaseContext(ctx context.Context, projectId, dbName string) (*sql.DatabaseInstance, error)
+	PatchAclEntriesOfDatabase(projectId, dbName string, entries []*sql.AclEntry) (*sql.Operation, error)
+	PatchAclEntriesOfDatabaseContext(ctx context.Context, projectId, dbName string, entries []*sql.AclEntry) (*sql.Operation, error)
+	GetFilteredAclEntriesOfDatabase(projectId, dbName string, notContain func(string) bool) ([]*sql.AclEntry, error)
+	GetFilteredAclEntriesOfDatabaseContext(ctx context.Context, projectId, dbName string, notContain func(string) bool) ([]*sql.AclEntry, error)
+}
+
+var _ Admin = (*CloudSqlManager)(nil)
+
 // CloudSqlManager
 //
 // https://godoc.org/google.golang.org/api/sqladmin/v1beta4
@@ -38,15 +61,22 @@ type CloudSqlManager struct {
 
 // GetDatabase gets the database instance
 func (manager *CloudSqlManager) GetDatabase(projectId, dbName string) (*sql.DatabaseInstance, error) {
+	return manager.GetDatabaseContext(context.Background(), projectId, dbName)
+}
+
+// GetDatabaseContext is GetDatabase with the context of the request
+func (manager *CloudSqlManager) GetDatabaseContext(
+	ctx context.Context, projectId, dbName string) (*sql.DatabaseInstance, error) {
+
 	log.Tracef("GetDatabase: projectId[%s], db[%s]", projectId, dbName)
 
 	dbInstanceService := sql.NewInstancesService(manager.Service)
 	if dbInstanceService == nil {
-		return nil, fmt.Errorf("Fail NewInstancesService")
+		return nil, gerror.Errorf("cloudsql", "GetDatabase", "Fail NewInstancesService")
 	}
 
-	if dbInstance, err := dbInstanceService.Get(projectId, dbName).Do(); err != nil {
-		return nil, err
+	if dbInstance, err := dbInstanceService.Get(projectId, dbName).Context(ctx).Do(); err != nil {
+		return nil, gerror.New("cloudsql", "GetDatabase", err)
 	} else {
 		for _, an := range dbInstance.Settings.IpConfiguration.AuthorizedNetworks {
 			log.Debugf("dbInstance: %+v", an)
@@ -57,9 +87,16 @@ func (manager *CloudSqlManager) GetDatabase(projectId, dbName string) (*sql.Data
 
 // PatchAclEntriesOfDatabase updates the aclEntries settings of the database instance
 func (manager *CloudSqlManager) PatchAclEntriesOfDatabase(projectId, dbName string, entries []*sql.AclEntry) (*sql.Operation, error) {
+	return manager.PatchAclEntriesOfDatabaseContext(context.Background(), projectId, dbName, entries)
+}
+
+// PatchAclEntriesOfDatabaseContext is PatchAclEntriesOfDatabase with the context of the requests
+func (manager *CloudSqlManager) PatchAclEntriesOfDatabaseContext(
+	ctx context.Context, projectId, dbName string, entries []*sql.AclEntry) (*sql.Operation, error) {
+
 	log.Debugf("PatchAclEntriesOfDatabase: projectId[%s], db[%s]", projectId, dbName)
 
-	dbInstance, err := manager.GetDatabase(projectId, dbName)
+	dbInstance, err := manager.GetDatabaseContext(ctx, projectId, dbName)
 	if err != nil {
 		return nil, err
 	}
@@ -68,19 +105,32 @@ func (manager *CloudSqlManager) PatchAclEntriesOfDatabase(projectId, dbName stri
 
 	dbInstanceService := sql.NewInstancesService(manager.Service)
 	if dbInstanceService == nil {
-		return nil, fmt.Errorf("Fail NewInstancesService")
+		return nil, gerror.Errorf("cloudsql", "PatchAclEntriesOfDatabase", "Fail NewInstancesService")
 	}
 
-	return dbInstanceService.Patch(projectId, dbName, dbInstance).Do()
+	// The patch sends the whole acl list, so it is safe to retry
+	op, err := dbInstanceService.Patch(projectId, dbName, dbInstance).Context(retry.WithIdempotent(ctx)).Do()
+	if err != nil {
+		return nil, gerror.New("cloudsql", "PatchAclEntriesOfDatabase", err)
+	}
+
+	return op, nil
 }
 
 // GetFilteredAclEntriesOfDatabase gets aclEntries which satisfies entry name filter
 func (manager *CloudSqlManager) GetFilteredAclEntriesOfDatabase(
 	projectId, dbName string, notContain func(string) bool) ([]*sql.AclEntry, error) {
 
+	return manager.GetFilteredAclEntriesOfDatabaseContext(context.Background(), projectId, dbName, notContain)
+}
+
+// GetFilteredAclEntriesOfDatabaseContext is GetFilteredAclEntriesOfDatabase with the context of the request
+func (manager *CloudSqlManager) GetFilteredAclEntriesOfDatabaseContext(
+	ctx context.Context, projectId, dbName string, notContain func(string) bool) ([]*sql.AclEntry, error) {
+
 	log.Debugf("GetFilteredAclEntriesOfDatabase: projectId[%s], db[%s]", projectId, dbName)
 
-	dbInstance, err := manager.GetDatabase(projectId, dbName)
+	dbInstance, err := manager.GetDatabaseContext(ctx, projectId, dbName)
 	if err != nil {
 		return nil, err
 	}
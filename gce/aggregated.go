@@ -0,0 +1,142 @@
+package gce
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/browny/gogoo/gerror"
+
+	log "github.com/cihub/seelog"
+	"golang.org/x/net/context"
+	compute "google.golang.org/api/compute/v1"
+)
+
+// noResultsOnPage is the warning of the scoped list of a zone with no resource
+const noResultsOnPage = "NO_RESULTS_ON_PAGE"
+
+// ListAllVms lists the VMs of all zones grouped by zone name, e.g. "asia-east1-b".
+// All pages are walked, and the zones without VMs are omitted.
+// https://godoc.org/google.golang.org/api/compute/v1#InstancesService.AggregatedList
+func (manager *GceManager) ListAllVms(projectId string, opts ...ListOption) (map[string][]*compute.Instance, error) {
+	return manager.ListAllVmsContext(context.Background(), projectId, opts...)
+}
+
+// ListAllVmsContext is ListAllVms with the context of the request
+func (manager *GceManager) ListAllVmsContext(
+	ctx context.Context, projectId string, opts ...ListOption) (map[string][]*compute.Instance, error) {
+
+	log.Tracef("List all VMs: project[%s]", projectId)
+
+	options := newListOptions(opts)
+	call := manager.Service.Instances.AggregatedList(projectId)
+	if options.filter != "" {
+		call.Filter(options.filter)
+	}
+	if options.maxResults > 0 {
+		call.MaxResults(options.maxResults)
+	}
+
+	vms := map[string][]*compute.Instance{}
+	err := call.Pages(ctx, func(page *compute.InstanceAggregatedList) error {
+		for scope, scoped := range page.Items {
+			if scoped.Warning != nil && scoped.Warning.Code != noResultsOnPage {
+				log.Warnf("List all VMs: scope[%s], warning[%s: %s]", scope, scoped.Warning.Code, scoped.Warning.Message)
+			}
+			if len(scoped.Instances) > 0 {
+				zone := nameOfLink(scope)
+				vms[zone] = append(vms[zone], scoped.Instances...)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, gceError("ListAllVms", err)
+	}
+
+	return vms, nil
+}
+
+// ListAllDisks lists the disks of all zones grouped by zone name, e.g. "asia-east1-b".
+// All pages are walked, and the zones without disks are omitted.
+// https://godoc.org/google.golang.org/api/compute/v1#DisksService.AggregatedList
+func (manager *GceManager) ListAllDisks(projectId string, opts ...ListOption) (map[string][]*compute.Disk, error) {
+	return manager.ListAllDisksContext(context.Background(), projectId, opts...)
+}
+
+// ListAllDisksContext is ListAllDisks with the context of the request
+func (manager *GceManager) ListAllDisksContext(
+	ctx context.Context, projectId string, opts ...ListOption) (map[string][]*compute.Disk, error) {
+
+	log.Tracef("List all disks: project[%s]", projectId)
+
+	options := newListOptions(opts)
+	call := manager.Service.Disks.AggregatedList(projectId)
+	if options.filter != "" {
+		call.Filter(options.filter)
+	}
+	if options.maxResults > 0 {
+		call.MaxResults(options.maxResults)
+	}
+
+	disks := map[string][]*compute.Disk{}
+	err := call.Pages(ctx, func(page *compute.DiskAggregatedList) error {
+		for scope, scoped := range page.Items {
+			if scoped.Warning != nil && scoped.Warning.Code != noResultsOnPage {
+				log.Warnf("List all disks: scope[%s], warning[%s: %s]", scope, scoped.Warning.Code, scoped.Warning.Message)
+			}
+			if len(scoped.Disks) > 0 {
+				zone := nameOfLink(scope)
+				disks[zone] = append(disks[zone], scoped.Disks...)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, gceError("ListAllDisks", err)
+	}
+
+	return disks, nil
+}
+
+// FindVmZone finds the zone of the VM by its name, so the methods taking zone can be called by name alone
+//
+//	zone, err := manager.FindVmZone(projectId, vmName)
+//	err = manager.DeleteVm(projectId, zone, vmName)
+//
+// The error is not found if there is no such VM, and it fails if VMs of the name are in more than one zone.
+func (manager *GceManager) FindVmZone(projectId, vmName string) (string, error) {
+	return manager.FindVmZoneContext(context.Background(), projectId, vmName)
+}
+
+// FindVmZoneContext is FindVmZone with the context of the request
+func (manager *GceManager) FindVmZoneContext(ctx context.Context, projectId, vmName string) (string, error) {
+	log.Tracef("Find VM zone: project[%s], vmName[%s]", projectId, vmName)
+
+	filter := NewFilter().NameMatches(regexp.QuoteMeta(vmName))
+	vms, err := manager.ListAllVmsContext(ctx, projectId, WithFilter(filter))
+	if err != nil {
+		// The error of ListAllVms tells the operation which fails
+		return "", err
+	}
+
+	zones := []string{}
+	for zone := range vms {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+
+	switch len(zones) {
+	case 0:
+		return "", &gerror.Error{
+			Service:   "gce",
+			Operation: "FindVmZone",
+			Reason:    gerror.ReasonNotFound,
+			Err:       fmt.Errorf("No VM found: project[%s], vmName[%s]", projectId, vmName),
+		}
+	case 1:
+		return zones[0], nil
+	default:
+		return "", gerror.Errorf("gce", "FindVmZone", "VM[%s] is in multiple zones: %v", vmName, zones)
+	}
+}
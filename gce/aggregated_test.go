@@ -0,0 +1,55 @@
+package gce_test
+
+import (
+	"testing"
+
+	"github.com/browny/gogoo/gce"
+	"github.com/browny/gogoo/gerror"
+	"github.com/browny/gogoo/gogootest"
+
+	"github.com/stretchr/testify/assert"
+	compute "google.golang.org/api/compute/v1"
+)
+
+func TestAggregated(t *testing.T) {
+	server, g := gogootest.NewGoGoo()
+	defer server.Close()
+
+	server.SetPageSize(2)
+	server.AddInstance(fakeZone, fakeVm("instance-a"))
+	server.AddInstance(fakeZone, fakeVm("instance-b"))
+	server.AddInstance("us-central1-a", fakeVm("instance-c"))
+	server.AddInstance("us-central1-a", fakeVm("instance-same"))
+	server.AddInstance("europe-west1-b", fakeVm("instance-same"))
+	server.AddDisk("europe-west1-b", &compute.Disk{Name: "disk-data"})
+
+	vms, err := g.ListAllVms(gogootest.ProjectId)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(vms))
+	assert.Equal(t, 2, len(vms[fakeZone]))
+	assert.Equal(t, "instance-c", vms["us-central1-a"][0].Name)
+	assert.Equal(t, "instance-same", vms["europe-west1-b"][0].Name)
+
+	vms, err = g.ListAllVms(gogootest.ProjectId, gce.WithFilter(gce.NewFilter().NameMatches("instance-[ab]")))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(vms))
+	assert.Equal(t, 2, len(vms[fakeZone]))
+
+	// The boot disks of the VMs are listed as well
+	disks, err := g.ListAllDisks(gogootest.ProjectId)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(disks["europe-west1-b"]))
+	assert.Equal(t, 2, len(disks[fakeZone]))
+
+	zone, err := g.FindVmZone(gogootest.ProjectId, "instance-c")
+	assert.Nil(t, err)
+	assert.Equal(t, "us-central1-a", zone)
+	assert.Nil(t, g.DeleteVm(gogootest.ProjectId, zone, "instance-c"))
+
+	_, err = g.FindVmZone(gogootest.ProjectId, "instance-c")
+	assert.True(t, gerror.IsNotFound(err))
+
+	_, err = g.FindVmZone(gogootest.ProjectId, "instance-same")
+	assert.NotNil(t, err)
+	assert.False(t, gerror.IsNotFound(err))
+}
@@ -0,0 +1,245 @@
+package gce
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/browny/gogoo/gerror"
+
+	log "github.com/cihub/seelog"
+	"golang.org/x/net/context"
+	compute "google.golang.org/api/compute/v1"
+)
+
+// Disk types of persistent disks
+const (
+	DiskTypeStandard = "pd-standard"
+	DiskTypeSsd      = "pd-ssd"
+)
+
+// DiskOption configures the disk created by CreateDisk
+type DiskOption func(*diskOptions)
+
+type diskOptions struct {
+	disk      compute.Disk
+	sourceKey *compute.CustomerEncryptionKey
+}
+
+// FromImage creates the disk from the image, which is the name of an image of the project,
+// or the partial or full url of an image of any project, e.g. "projects/debian-cloud/global/images/debian-8-jessie-v20160606"
+func FromImage(image string) DiskOption {
+	return func(options *diskOptions) {
+		if !strings.Contains(image, "/") {
+			image = "global/images/" + image
+		}
+		options.disk.SourceImage = image
+	}
+}
+
+// FromImageFamily creates the disk from the latest image of the family in the project, e.g. "debian-cloud", "debian-8"
+func FromImageFamily(imageProject, family string) DiskOption {
+	return func(options *diskOptions) {
+		options.disk.SourceImage = fmt.Sprintf("projects/%s/global/images/family/%s", imageProject, family)
+	}
+}
+
+// FromSnapshot creates the disk from the snapshot, which is the name or the url of a snapshot
+func FromSnapshot(snapshot string) DiskOption {
+	return func(options *diskOptions) {
+		if !strings.Contains(snapshot, "/") {
+			snapshot = "global/snapshots/" + snapshot
+		}
+		options.disk.SourceSnapshot = snapshot
+	}
+}
+
+// WithSizeGb sets the size of the disk, which is the size of the source by default
+func WithSizeGb(sizeGb int64) DiskOption {
+	return func(options *diskOptions) {
+		options.disk.SizeGb = sizeGb
+	}
+}
+
+// WithDiskType sets the type of the disk, e.g. DiskTypeSsd, which is DiskTypeStandard by default
+func WithDiskType(diskType string) DiskOption {
+	return func(options *diskOptions) {
+		options.disk.Type = diskType
+	}
+}
+
+// WithDiskLabels sets the labels of the disk
+func WithDiskLabels(labels map[string]string) DiskOption {
+	return func(options *diskOptions) {
+		options.disk.Labels = labels
+	}
+}
+
+// WithDiskEncryptionKey encrypts the disk by the customer supplied key
+func WithDiskEncryptionKey(key *compute.CustomerEncryptionKey) DiskOption {
+	return func(options *diskOptions) {
+		options.disk.DiskEncryptionKey = key
+	}
+}
+
+// WithSourceEncryptionKey decrypts the source image or snapshot by the customer supplied key
+func WithSourceEncryptionKey(key *compute.CustomerEncryptionKey) DiskOption {
+	return func(options *diskOptions) {
+		options.sourceKey = key
+	}
+}
+
+// CreateDisk creates the disk, which is blank without FromImage, FromImageFamily or FromSnapshot.
+// It blocks till the disk is READY, and returns the created disk.
+// https://godoc.org/google.golang.org/api/compute/v1#DisksService.Insert
+func (manager *GceManager) CreateDisk(projectId, zone, name string, opts ...DiskOption) (*compute.Disk, error) {
+	return manager.CreateDiskContext(context.Background(), projectId, zone, name, opts...)
+}
+
+// CreateDiskContext is CreateDisk with the context of the requests
+func (manager *GceManager) CreateDiskContext(
+	ctx context.Context, projectId, zone, name string, opts ...DiskOption) (*compute.Disk, error) {
+
+	options := &diskOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	disk := &options.disk
+	disk.Name = name
+	if disk.Type != "" && !strings.Contains(disk.Type, "/") {
+		disk.Type = fmt.Sprintf("zones/%s/diskTypes/%s", zone, disk.Type)
+	}
+	if options.sourceKey != nil {
+		if disk.SourceSnapshot != "" {
+			disk.SourceSnapshotEncryptionKey = options.sourceKey
+		} else {
+			disk.SourceImageEncryptionKey = options.sourceKey
+		}
+	}
+	if disk.SourceImage == "" && disk.SourceSnapshot == "" && disk.SizeGb <= 0 {
+		return nil, gceError("CreateDisk", fmt.Errorf("size of blank disk[%s] is required", name))
+	}
+
+	log.Tracef("Create disk: project[%s], zone[%s], name[%s], sourceImage[%s], sourceSnapshot[%s], type[%s]",
+		projectId, zone, name, disk.SourceImage, disk.SourceSnapshot, disk.Type)
+
+	if err := manager.insertDisk(ctx, projectId, zone, disk); err != nil {
+		return nil, gceError("CreateDisk", err)
+	}
+
+	created, err := manager.Service.Disks.Get(projectId, zone, name).Context(ctx).Do()
+
+	return created, gceError("CreateDisk", err)
+}
+
+// insertDisk inserts the disk and waits till it is READY, the error is not wrapped
+func (manager *GceManager) insertDisk(ctx context.Context, projectId, zone string, disk *compute.Disk) error {
+	op, err := manager.Service.Disks.Insert(projectId, zone, disk).
+		RequestId(newRequestId()).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	if _, err := manager.waitOperation(ctx, op); err != nil {
+		return err
+	}
+
+	return manager.waitStatus(ctx, projectId, KindDisk, zone, disk.Name, "READY",
+		orDefault(manager.Timeouts.DiskCreation, DiskCreationTimeout))
+}
+
+// AttachDisk attaches the disk to the VM, and blocks till the operation is DONE.
+// The source of `attached` is the name or the url of the disk, and the device name is the name
+// of the disk by default.
+// https://godoc.org/google.golang.org/api/compute/v1#InstancesService.AttachDisk
+func (manager *GceManager) AttachDisk(projectId, zone, vmName string, attached *compute.AttachedDisk) error {
+	return manager.AttachDiskContext(context.Background(), projectId, zone, vmName, attached)
+}
+
+// AttachDiskContext is AttachDisk with the context of the requests
+func (manager *GceManager) AttachDiskContext(
+	ctx context.Context, projectId, zone, vmName string, attached *compute.AttachedDisk) error {
+
+	if attached == nil {
+		return gceError("AttachDisk", fmt.Errorf("attached disk is nil"))
+	}
+
+	log.Tracef("Attach disk: project[%s], zone[%s], vmName[%s], disk[%s]", projectId, zone, vmName, attached.Source)
+
+	// The disk of the caller is kept as given
+	disk := *attached
+	if !strings.Contains(disk.Source, "/") {
+		disk.Source = fmt.Sprintf("zones/%s/disks/%s", zone, disk.Source)
+	}
+
+	op, err := manager.Service.Instances.AttachDisk(projectId, zone, vmName, &disk).Context(ctx).Do()
+	if err != nil {
+		return gceError("AttachDisk", err)
+	}
+	_, err = manager.waitOperation(ctx, op)
+
+	return gceError("AttachDisk", err)
+}
+
+// DetachDisk detaches the disk from the VM by the name of the disk or its device name,
+// and blocks till the operation is DONE. The error has ReasonNotFound if the disk is not attached.
+// https://godoc.org/google.golang.org/api/compute/v1#InstancesService.DetachDisk
+func (manager *GceManager) DetachDisk(projectId, zone, vmName, diskName string) error {
+	return manager.DetachDiskContext(context.Background(), projectId, zone, vmName, diskName)
+}
+
+// DetachDiskContext is DetachDisk with the context of the requests
+func (manager *GceManager) DetachDiskContext(ctx context.Context, projectId, zone, vmName, diskName string) error {
+	log.Tracef("Detach disk: project[%s], zone[%s], vmName[%s], disk[%s]", projectId, zone, vmName, diskName)
+
+	vm, err := manager.Service.Instances.Get(projectId, zone, vmName).Context(ctx).Do()
+	if err != nil {
+		return gceError("DetachDisk", err)
+	}
+
+	deviceName := ""
+	for _, attached := range vm.Disks {
+		if attached.DeviceName == diskName || (attached.Source != "" && nameOfLink(attached.Source) == diskName) {
+			deviceName = attached.DeviceName
+			break
+		}
+	}
+	if deviceName == "" {
+		return &gerror.Error{
+			Service:   "gce",
+			Operation: "DetachDisk",
+			Reason:    gerror.ReasonNotFound,
+			Err:       fmt.Errorf("disk[%s] is not attached to vm[%s]", diskName, vmName),
+		}
+	}
+
+	op, err := manager.Service.Instances.DetachDisk(projectId, zone, vmName, deviceName).Context(ctx).Do()
+	if err != nil {
+		return gceError("DetachDisk", err)
+	}
+	_, err = manager.waitOperation(ctx, op)
+
+	return gceError("DetachDisk", err)
+}
+
+// ResizeDisk enlarges the disk to sizeGb, and blocks till the operation is DONE.
+// The file system of the disk has to be resized in the VM afterwards.
+// https://godoc.org/google.golang.org/api/compute/v1#DisksService.Resize
+func (manager *GceManager) ResizeDisk(projectId, zone, diskName string, sizeGb int64) error {
+	return manager.ResizeDiskContext(context.Background(), projectId, zone, diskName, sizeGb)
+}
+
+// ResizeDiskContext is ResizeDisk with the context of the requests
+func (manager *GceManager) ResizeDiskContext(
+	ctx context.Context, projectId, zone, diskName string, sizeGb int64) error {
+
+	log.Tracef("Resize disk: project[%s], zone[%s], disk[%s], sizeGb[%d]", projectId, zone, diskName, sizeGb)
+
+	request := &compute.DisksResizeRequest{SizeGb: sizeGb}
+	op, err := manager.Service.Disks.Resize(projectId, zone, diskName, request).Context(ctx).Do()
+	if err != nil {
+		return gceError("ResizeDisk", err)
+	}
+	_, err = manager.waitOperation(ctx, op)
+
+	return gceError("ResizeDisk", err)
+}
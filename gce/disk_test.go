@@ -0,0 +1,83 @@
+package gce_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/browny/gogoo/gce"
+	"github.com/browny/gogoo/gerror"
+	"github.com/browny/gogoo/gogootest"
+
+	"github.com/stretchr/testify/assert"
+	compute "google.golang.org/api/compute/v1"
+)
+
+func TestDisk(t *testing.T) {
+	server, g := gogootest.NewGoGoo()
+	defer server.Close()
+
+	server.AddImage(&compute.Image{Name: "image-old", Family: "base", DiskSizeGb: 10,
+		CreationTimestamp: "2016-06-01T00:00:00Z"})
+	server.AddImage(&compute.Image{Name: "image-new", Family: "base", DiskSizeGb: 20,
+		CreationTimestamp: "2016-06-02T00:00:00Z"})
+	server.AddInstance(fakeZone, fakeVm("instance-test"))
+	server.SetStatusTransitions(true)
+
+	disk, err := g.CreateDisk(gogootest.ProjectId, fakeZone, "disk-family",
+		gce.FromImageFamily(gogootest.ProjectId, "base"), gce.WithDiskType(gce.DiskTypeSsd),
+		gce.WithDiskLabels(map[string]string{"env": "test"}))
+	assert.Nil(t, err)
+	assert.Equal(t, "READY", disk.Status)
+	assert.True(t, strings.HasSuffix(disk.SourceImage, "/global/images/image-new"))
+	assert.True(t, strings.HasSuffix(disk.Type, "/zones/asia-east1-b/diskTypes/pd-ssd"))
+	assert.Equal(t, int64(20), disk.SizeGb)
+	assert.Equal(t, "test", disk.Labels["env"])
+
+	key := &compute.CustomerEncryptionKey{RawKey: "SGVsbG8gZnJvbSBHb29nbGUgQ2xvdWQgUGxhdGZvcm0="}
+	disk, err = g.CreateDisk(gogootest.ProjectId, fakeZone, "disk-blank",
+		gce.WithSizeGb(50), gce.WithDiskEncryptionKey(key))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(50), disk.SizeGb)
+	assert.True(t, strings.HasSuffix(disk.Type, "/diskTypes/pd-standard"))
+	assert.Empty(t, disk.SourceImage)
+
+	disk, err = g.CreateDisk(gogootest.ProjectId, fakeZone, "disk-image", gce.FromImage("image-old"))
+	assert.Nil(t, err)
+	assert.Equal(t, "global/images/image-old", disk.SourceImage)
+
+	_, err = g.CreateDisk(gogootest.ProjectId, fakeZone, "disk-no-size")
+	assert.NotNil(t, err)
+	_, err = g.CreateDisk(gogootest.ProjectId, fakeZone, "disk-no-family", gce.FromImageFamily(gogootest.ProjectId, "none"))
+	assert.True(t, gerror.IsNotFound(err))
+
+	// Attach, resize and detach
+	server.SetStatusTransitions(false)
+	attached := &compute.AttachedDisk{Source: "disk-blank", Mode: "READ_WRITE"}
+	assert.NotNil(t, g.AttachDisk(gogootest.ProjectId, fakeZone, "instance-test", nil))
+	assert.Nil(t, g.AttachDisk(gogootest.ProjectId, fakeZone, "instance-test", attached))
+	assert.Equal(t, "disk-blank", attached.Source)
+	vm, _ := g.GetVm(gogootest.ProjectId, fakeZone, "instance-test")
+	assert.Len(t, vm.Disks, 2)
+	assert.Equal(t, "disk-blank", vm.Disks[1].DeviceName)
+	assert.True(t, strings.HasSuffix(vm.Disks[1].Source, "/disks/disk-blank"))
+	disk, _ = g.GetDisk(gogootest.ProjectId, fakeZone, "disk-blank")
+	assert.Equal(t, []string{vm.SelfLink}, disk.Users)
+
+	server.AddInstance(fakeZone, fakeVm("instance-other"))
+	err = g.AttachDisk(gogootest.ProjectId, fakeZone, "instance-other", &compute.AttachedDisk{Source: "disk-blank"})
+	assert.Equal(t, "resourceInUseByAnotherResource", err.(*gerror.Error).Reason)
+
+	assert.Nil(t, g.ResizeDisk(gogootest.ProjectId, fakeZone, "disk-blank", 100))
+	disk, _ = g.GetDisk(gogootest.ProjectId, fakeZone, "disk-blank")
+	assert.Equal(t, int64(100), disk.SizeGb)
+	assert.NotNil(t, g.ResizeDisk(gogootest.ProjectId, fakeZone, "disk-blank", 10))
+
+	assert.Nil(t, g.DetachDisk(gogootest.ProjectId, fakeZone, "instance-test", "disk-blank"))
+	vm, _ = g.GetVm(gogootest.ProjectId, fakeZone, "instance-test")
+	assert.Len(t, vm.Disks, 1)
+	disk, _ = g.GetDisk(gogootest.ProjectId, fakeZone, "disk-blank")
+	assert.Empty(t, disk.Users)
+
+	err = g.DetachDisk(gogootest.ProjectId, fakeZone, "instance-test", "disk-blank")
+	assert.True(t, gerror.IsNotFound(err))
+}
@@ -0,0 +1,29 @@
+package gce_test
+
+import (
+	"fmt"
+
+	compute "google.golang.org/api/compute/v1"
+)
+
+const fakeZone = "asia-east1-b"
+
+func fakeVm(name string) *compute.Instance {
+	return &compute.Instance{
+		Name:        name,
+		MachineType: fmt.Sprintf("zones/%s/machineTypes/n1-standard-1", fakeZone),
+		Disks: []*compute.AttachedDisk{
+			&compute.AttachedDisk{
+				Boot:             true,
+				AutoDelete:       true,
+				InitializeParams: &compute.AttachedDiskInitializeParams{SourceImage: "global/images/image-test"},
+			},
+		},
+		NetworkInterfaces: []*compute.NetworkInterface{
+			&compute.NetworkInterface{
+				Network:       "global/networks/default",
+				AccessConfigs: []*compute.AccessConfig{&compute.AccessConfig{Type: "ONE_TO_ONE_NAT"}},
+			},
+		},
+	}
+}
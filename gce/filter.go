@@ -0,0 +1,223 @@
+package gce
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	labelKeyPattern   = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,62}$`)
+	labelValuePattern = regexp.MustCompile(`^[a-z0-9_-]{0,63}$`)
+)
+
+// Filter builds the `filter` expression of the list requests of compute engine,
+// so only the matched resources are responded.
+// The conditions are ANDed, and compiled to the regular expression form of the api, e.g.
+//
+//	NewFilter().Status("RUNNING").Label("env", "prod").String()
+//	// (status eq "RUNNING") (labels.env eq "prod")
+//
+// https://cloud.google.com/compute/docs/reference/rest/v1/instances/list
+type Filter struct {
+	expressions []string
+}
+
+// NewFilter creates an empty filter, which matches all resources
+func NewFilter() *Filter {
+	return &Filter{}
+}
+
+// ParseLabelSelector builds the filter by the comma separated label requirements, e.g. "env=prod,team!=infra".
+// A requirement is `key=value`, `key==value`, `key!=value`, or `key` to match the labeled resources.
+func ParseLabelSelector(selector string) (*Filter, error) {
+	requirements, err := parseLabelRequirements(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := NewFilter()
+	for _, requirement := range requirements {
+		switch requirement.operator {
+		case "":
+			filter.HasLabel(requirement.key)
+		case "!=":
+			filter.LabelNot(requirement.key, requirement.value)
+		default:
+			filter.Label(requirement.key, requirement.value)
+		}
+	}
+
+	return filter, nil
+}
+
+// labelRequirement is a requirement of the label selector, operator is empty if only the key is required
+type labelRequirement struct {
+	key      string
+	operator string
+	value    string
+}
+
+func parseLabelRequirements(selector string) ([]labelRequirement, error) {
+	requirements := []labelRequirement{}
+	for _, requirement := range strings.Split(selector, ",") {
+		requirement = strings.TrimSpace(requirement)
+		if requirement == "" {
+			continue
+		}
+
+		var key, value, operator string
+		switch {
+		case strings.Contains(requirement, "!="):
+			operator = "!="
+		case strings.Contains(requirement, "=="):
+			operator = "=="
+		case strings.Contains(requirement, "="):
+			operator = "="
+		}
+		if operator == "" {
+			key = requirement
+		} else {
+			pair := strings.SplitN(requirement, operator, 2)
+			key, value = strings.TrimSpace(pair[0]), strings.TrimSpace(pair[1])
+		}
+
+		if !labelKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid label key[%s] in selector[%s]", key, selector)
+		}
+		if !labelValuePattern.MatchString(value) {
+			return nil, fmt.Errorf("invalid label value[%s] in selector[%s]", value, selector)
+		}
+
+		requirements = append(requirements, labelRequirement{key: key, operator: operator, value: value})
+	}
+
+	return requirements, nil
+}
+
+// matchLabels tells whether the labels meet all requirements, as the filter built by them does
+func matchLabels(requirements []labelRequirement, labels map[string]string) bool {
+	for _, requirement := range requirements {
+		value, ok := labels[requirement.key]
+		switch requirement.operator {
+		case "":
+			if !ok || value == "" {
+				return false
+			}
+		case "!=":
+			if value == requirement.value {
+				return false
+			}
+		default:
+			if value != requirement.value {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// NameMatches matches the resources whose whole name matches the RE2 regular expression
+func (f *Filter) NameMatches(regex string) *Filter {
+	return f.add("name", "eq", regex)
+}
+
+// Status matches the resources in any of the statuses, e.g. "RUNNING", "TERMINATED"
+func (f *Filter) Status(statuses ...string) *Filter {
+	return f.add("status", "eq", quoteAll(statuses))
+}
+
+// Label matches the resources labeled key=value
+func (f *Filter) Label(key, value string) *Filter {
+	return f.add("labels."+key, "eq", regexp.QuoteMeta(value))
+}
+
+// LabelNot matches the resources not labeled key=value
+func (f *Filter) LabelNot(key, value string) *Filter {
+	return f.add("labels."+key, "ne", regexp.QuoteMeta(value))
+}
+
+// HasLabel matches the resources labeled with key
+func (f *Filter) HasLabel(key string) *Filter {
+	return f.add("labels."+key, "eq", ".+")
+}
+
+// Tag matches the instances having any of the network tags
+func (f *Filter) Tag(tags ...string) *Filter {
+	return f.add("tags.items", "eq", quoteAll(tags))
+}
+
+// MachineType matches the instances of any of the machine types, e.g. "n1-standard-1"
+func (f *Filter) MachineType(machineTypes ...string) *Filter {
+	return f.add("machineType", "eq", ".*/machineTypes/"+quoteAll(machineTypes))
+}
+
+// And adds all conditions of other filter
+func (f *Filter) And(other *Filter) *Filter {
+	if other != nil {
+		f.expressions = append(f.expressions, other.expressions...)
+	}
+
+	return f
+}
+
+// String compiles the filter to the expression of the api, it is empty if there is no condition
+func (f *Filter) String() string {
+	if f == nil {
+		return ""
+	}
+
+	return strings.Join(f.expressions, " ")
+}
+
+func (f *Filter) add(field, operator, regex string) *Filter {
+	regex = strings.Replace(regex, `"`, `\"`, -1)
+	f.expressions = append(f.expressions, fmt.Sprintf(`(%s %s "%s")`, field, operator, regex))
+
+	return f
+}
+
+// quoteAll builds the regular expression matching any of the literals
+func quoteAll(literals []string) string {
+	quoted := make([]string, len(literals))
+	for i, literal := range literals {
+		quoted[i] = regexp.QuoteMeta(literal)
+	}
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+
+	return "(" + strings.Join(quoted, "|") + ")"
+}
+
+// ListOption configures the list requests of GceManager
+type ListOption func(*listOptions)
+
+type listOptions struct {
+	filter     string
+	maxResults int64
+}
+
+// WithFilter makes the list request respond only the resources matched by filter
+func WithFilter(filter *Filter) ListOption {
+	return func(options *listOptions) {
+		options.filter = filter.String()
+	}
+}
+
+// WithPageSize sets the max number of resources of a page, which is 500 by default
+func WithPageSize(n int64) ListOption {
+	return func(options *listOptions) {
+		options.maxResults = n
+	}
+}
+
+func newListOptions(opts []ListOption) listOptions {
+	options := listOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return options
+}
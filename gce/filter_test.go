@@ -0,0 +1,106 @@
+package gce_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/browny/gogoo/gce"
+	"github.com/browny/gogoo/gogootest"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/iterator"
+)
+
+func TestFilter(t *testing.T) {
+	assert.Equal(t, "", gce.NewFilter().String())
+
+	filter := gce.NewFilter().
+		NameMatches("instance-.*").
+		Status("RUNNING", "STOPPING").
+		Label("env", "prod").
+		Tag("http-server").
+		MachineType("n1-standard-1")
+	assert.Equal(t, `(name eq "instance-.*") (status eq "(RUNNING|STOPPING)") (labels.env eq "prod") `+
+		`(tags.items eq "http-server") (machineType eq ".*/machineTypes/n1-standard-1")`, filter.String())
+
+	assert.Equal(t, `(name eq "a\"b")`, gce.NewFilter().NameMatches(`a"b`).String())
+}
+
+func TestParseLabelSelector(t *testing.T) {
+	filter, err := gce.ParseLabelSelector("env=prod, team!=infra,tier==web,canary")
+	assert.Nil(t, err)
+	assert.Equal(t, `(labels.env eq "prod") (labels.team ne "infra") (labels.tier eq "web") (labels.canary eq ".+")`,
+		filter.String())
+
+	filter, err = gce.ParseLabelSelector("")
+	assert.Nil(t, err)
+	assert.Equal(t, "", filter.String())
+
+	_, err = gce.ParseLabelSelector("Env=prod")
+	assert.NotNil(t, err)
+	_, err = gce.ParseLabelSelector("env=prod!")
+	assert.NotNil(t, err)
+	_, err = gce.ParseLabelSelector("=prod")
+	assert.NotNil(t, err)
+}
+
+func TestListFilter(t *testing.T) {
+	server, g := gogootest.NewGoGoo()
+	defer server.Close()
+
+	for i, env := range []string{"prod", "prod", "dev"} {
+		vm := fakeVm(fmt.Sprintf("instance-%d", i))
+		vm.Labels = map[string]string{"env": env, "team": "infra"}
+		vm.Tags = &compute.Tags{Items: []string{"http-server", fmt.Sprintf("tag-%d", i)}}
+		server.AddInstance(fakeZone, vm)
+	}
+	vm := fakeVm("worker")
+	vm.MachineType = fmt.Sprintf("zones/%s/machineTypes/f1-micro", fakeZone)
+	vm.Labels = map[string]string{"env": "prod", "team": "data"}
+	server.AddInstance(fakeZone, vm)
+	g.StopVm(gogootest.ProjectId, fakeZone, "instance-1", nil, gce.WaitUntilDone())
+
+	names := func(filter *gce.Filter) []string {
+		vms, err := g.ListVms(gogootest.ProjectId, fakeZone, gce.WithFilter(filter))
+		assert.Nil(t, err)
+		names := []string{}
+		for _, vm := range vms.Items {
+			names = append(names, vm.Name)
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"instance-0", "instance-1", "instance-2"}, names(gce.NewFilter().NameMatches("instance-.*")))
+	assert.Equal(t, []string{"instance-0", "instance-2", "worker"}, names(gce.NewFilter().Status("RUNNING")))
+	assert.Equal(t, []string{"instance-1"}, names(gce.NewFilter().Status("TERMINATED", "STOPPING")))
+	assert.Equal(t, []string{"instance-2"}, names(gce.NewFilter().Tag("tag-2")))
+	assert.Equal(t, []string{"worker"}, names(gce.NewFilter().MachineType("f1-micro")))
+	assert.Equal(t, []string{"instance-0", "instance-1", "instance-2", "worker"}, names(nil))
+
+	selector, err := gce.ParseLabelSelector("env=prod,team!=infra")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"worker"}, names(selector))
+
+	selector, _ = gce.ParseLabelSelector("env=prod")
+	assert.Equal(t, []string{"instance-0"}, names(selector.And(gce.NewFilter().Status("RUNNING").Tag("http-server"))))
+
+	// The boot disks of the VMs are named after them
+	disks, err := g.ListDisks(gogootest.ProjectId, fakeZone, gce.WithFilter(gce.NewFilter().NameMatches("worker")))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(disks.Items))
+
+	// Filter and page size apply to the iterators
+	it := g.IterateVms(context.Background(), gogootest.ProjectId, fakeZone,
+		gce.WithFilter(gce.NewFilter().Label("env", "prod")), gce.WithPageSize(1))
+	count := 0
+	for _, err := it.Next(); err != iterator.Done; _, err = it.Next() {
+		assert.Nil(t, err)
+		count++
+	}
+	assert.Equal(t, 3, count)
+
+	_, err = g.ListVms(gogootest.ProjectId, fakeZone, gce.WithFilter(gce.NewFilter().NameMatches("(")))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,226 @@
+package gce
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+	"golang.org/x/net/context"
+	compute "google.golang.org/api/compute/v1"
+)
+
+// FleetConcurrency is the number of VMs handled at the same time by the fleet methods by default
+const FleetConcurrency = 10
+
+// VmRef refers to a VM by its zone and name
+type VmRef struct {
+	Zone string
+	Name string
+}
+
+// VmSpec is the VM to create in the zone
+type VmSpec struct {
+	Zone string
+	Vm   *compute.Instance
+}
+
+// FleetResult is the result of one VM in a fleet operation
+type FleetResult struct {
+	VmRef
+	// Err is the cause of failure, nil if the VM reaches the target state
+	Err error
+	// Elapsed is the time from the request to the target state or the failure
+	Elapsed time.Duration
+}
+
+// FleetReport reports the result of each VM in a fleet operation, in the order of the requested VMs
+type FleetReport struct {
+	Results []*FleetResult
+}
+
+// Succeeded returns the results of the VMs reaching the target state
+func (report *FleetReport) Succeeded() []*FleetResult {
+	results := []*FleetResult{}
+	for _, result := range report.Results {
+		if result.Err == nil {
+			results = append(results, result)
+		}
+	}
+
+	return results
+}
+
+// Failed returns the results of the failed VMs
+func (report *FleetReport) Failed() []*FleetResult {
+	results := []*FleetResult{}
+	for _, result := range report.Results {
+		if result.Err != nil {
+			results = append(results, result)
+		}
+	}
+
+	return results
+}
+
+// Err summarizes the failures, it is nil if all VMs succeed
+func (report *FleetReport) Err() error {
+	failed := report.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+
+	causes := []string{}
+	for _, result := range failed {
+		causes = append(causes, fmt.Sprintf("%s/%s: %v", result.Zone, result.Name, result.Err))
+	}
+
+	return fmt.Errorf("%d of %d VMs fail: %s", len(failed), len(report.Results), strings.Join(causes, "; "))
+}
+
+// FleetOption configures the fleet methods of GceManager
+type FleetOption func(*fleetOptions)
+
+type fleetOptions struct {
+	concurrency int
+}
+
+// WithConcurrency sets the max number of VMs handled at the same time, `FleetConcurrency` by default
+func WithConcurrency(n int) FleetOption {
+	return func(options *fleetOptions) {
+		options.concurrency = n
+	}
+}
+
+// NewVms creates the VMs in parallel, it blocks till all VMs are RUNNING or fail.
+// A failed VM does not stop the others, the result of each VM is in the report.
+func (manager *GceManager) NewVms(projectId string, specs []VmSpec, opts ...FleetOption) *FleetReport {
+	return manager.NewVmsContext(context.Background(), projectId, specs, opts...)
+}
+
+// NewVmsContext is NewVms with the context of the requests
+func (manager *GceManager) NewVmsContext(
+	ctx context.Context, projectId string, specs []VmSpec, opts ...FleetOption) *FleetReport {
+
+	log.Tracef("New VMs: project[%s], count[%d]", projectId, len(specs))
+
+	refs := make([]VmRef, len(specs))
+	for i, spec := range specs {
+		refs[i] = VmRef{Zone: spec.Zone}
+		if spec.Vm != nil {
+			refs[i].Name = spec.Vm.Name
+		}
+	}
+
+	return runFleet(ctx, refs, opts, func(ctx context.Context, i int) error {
+		if specs[i].Vm == nil {
+			return fmt.Errorf("vm is nil")
+		}
+		return manager.NewVmContext(ctx, projectId, specs[i].Zone, specs[i].Vm)
+	})
+}
+
+// StartVms starts the VMs in parallel, it blocks till all VMs are RUNNING or fail
+func (manager *GceManager) StartVms(projectId string, vms []VmRef, opts ...FleetOption) *FleetReport {
+	return manager.StartVmsContext(context.Background(), projectId, vms, opts...)
+}
+
+// StartVmsContext is StartVms with the context of the requests
+func (manager *GceManager) StartVmsContext(
+	ctx context.Context, projectId string, vms []VmRef, opts ...FleetOption) *FleetReport {
+
+	log.Tracef("Start VMs: project[%s], count[%d]", projectId, len(vms))
+
+	return runFleet(ctx, vms, opts, func(ctx context.Context, i int) error {
+		if _, err := manager.StartVmContext(ctx, projectId, vms[i].Zone, vms[i].Name, nil, WaitUntilDone()); err != nil {
+			return err
+		}
+		return manager.waitStatus(ctx, projectId, KindInstance, vms[i].Zone, vms[i].Name, "RUNNING",
+			orDefault(manager.Timeouts.VmRunning, VmRunningTimeout))
+	})
+}
+
+// StopVms stops the VMs in parallel, it blocks till all VMs are TERMINATED or fail
+func (manager *GceManager) StopVms(projectId string, vms []VmRef, opts ...FleetOption) *FleetReport {
+	return manager.StopVmsContext(context.Background(), projectId, vms, opts...)
+}
+
+// StopVmsContext is StopVms with the context of the requests
+func (manager *GceManager) StopVmsContext(
+	ctx context.Context, projectId string, vms []VmRef, opts ...FleetOption) *FleetReport {
+
+	log.Tracef("Stop VMs: project[%s], count[%d]", projectId, len(vms))
+
+	return runFleet(ctx, vms, opts, func(ctx context.Context, i int) error {
+		if _, err := manager.StopVmContext(ctx, projectId, vms[i].Zone, vms[i].Name, nil, WaitUntilDone()); err != nil {
+			return err
+		}
+		return manager.waitStatus(ctx, projectId, KindInstance, vms[i].Zone, vms[i].Name, "TERMINATED",
+			orDefault(manager.Timeouts.VmStopping, VmStoppingTimeout))
+	})
+}
+
+// DeleteVms deletes the VMs in parallel, it blocks till all VMs are not found or fail.
+// The VM still existing after `Timeouts.Deletion` of the manager fails.
+func (manager *GceManager) DeleteVms(projectId string, vms []VmRef, opts ...FleetOption) *FleetReport {
+	return manager.DeleteVmsContext(context.Background(), projectId, vms, opts...)
+}
+
+// DeleteVmsContext is DeleteVms with the context of the requests
+func (manager *GceManager) DeleteVmsContext(
+	ctx context.Context, projectId string, vms []VmRef, opts ...FleetOption) *FleetReport {
+
+	log.Tracef("Delete VMs: project[%s], count[%d]", projectId, len(vms))
+
+	return runFleet(ctx, vms, opts, func(ctx context.Context, i int) error {
+		return manager.DeleteVmContext(ctx, projectId, vms[i].Zone, vms[i].Name, WaitUntilDeleted(0))
+	})
+}
+
+// runFleet calls do for each VM with bounded concurrency and collects the results.
+// The VMs not started before ctx is done fail with the error of ctx.
+func runFleet(ctx context.Context, vms []VmRef, opts []FleetOption,
+	do func(ctx context.Context, i int) error) *FleetReport {
+
+	options := fleetOptions{concurrency: FleetConcurrency}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.concurrency <= 0 {
+		options.concurrency = FleetConcurrency
+	}
+
+	report := &FleetReport{Results: make([]*FleetResult, len(vms))}
+	semaphore := make(chan struct{}, options.concurrency)
+	var wg sync.WaitGroup
+	for i := range vms {
+		result := &FleetResult{VmRef: vms[i]}
+		report.Results[i] = result
+
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			result.Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			start := time.Now()
+			result.Err = do(ctx, i)
+			result.Elapsed = time.Since(start)
+			if result.Err != nil {
+				log.Warnf("Fleet VM fails: zone[%s], vmName[%s], err[%s]", result.Zone, result.Name, result.Err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	return report
+}
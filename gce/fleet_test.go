@@ -0,0 +1,70 @@
+package gce_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/browny/gogoo/gce"
+	"github.com/browny/gogoo/gerror"
+	"github.com/browny/gogoo/gogootest"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func TestFleet(t *testing.T) {
+	server, g := gogootest.NewGoGoo()
+	defer server.Close()
+
+	server.SetOperationPolls(2)
+	server.AddInstance(fakeZone, fakeVm("instance-existed"))
+
+	specs := []gce.VmSpec{}
+	refs := []gce.VmRef{}
+	for i := 0; i < 5; i++ {
+		name := fmt.Sprintf("instance-%d", i)
+		specs = append(specs, gce.VmSpec{Zone: fakeZone, Vm: fakeVm(name)})
+		refs = append(refs, gce.VmRef{Zone: fakeZone, Name: name})
+	}
+	specs = append(specs, gce.VmSpec{Zone: fakeZone, Vm: fakeVm("instance-existed")})
+
+	report := g.NewVms(gogootest.ProjectId, specs, gce.WithConcurrency(2))
+	assert.Equal(t, 6, len(report.Results))
+	assert.Equal(t, 5, len(report.Succeeded()))
+	assert.Equal(t, 1, len(report.Failed()))
+	assert.Equal(t, "instance-existed", report.Failed()[0].Name)
+	assert.True(t, gerror.IsAlreadyExists(report.Failed()[0].Err))
+	assert.Equal(t, "instance-0", report.Results[0].Name)
+	assert.True(t, report.Results[0].Elapsed > 0)
+	assert.NotNil(t, report.Err())
+
+	report = g.StopVms(gogootest.ProjectId, refs)
+	assert.Nil(t, report.Err())
+	for _, ref := range refs {
+		vm, _ := g.GetVm(gogootest.ProjectId, ref.Zone, ref.Name)
+		assert.Equal(t, "TERMINATED", vm.Status)
+	}
+
+	report = g.StartVms(gogootest.ProjectId, append(refs, gce.VmRef{Zone: fakeZone, Name: "instance-not-existed"}))
+	assert.Equal(t, 5, len(report.Succeeded()))
+	assert.True(t, gerror.IsNotFound(report.Failed()[0].Err))
+	vm, _ := g.GetVm(gogootest.ProjectId, fakeZone, "instance-4")
+	assert.Equal(t, "RUNNING", vm.Status)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report = g.DeleteVmsContext(ctx, gogootest.ProjectId, refs)
+	assert.Equal(t, 5, len(report.Failed()))
+
+	// The deleted VMs are gone once DeleteVms returns
+	server.SetDeletionPolls(2)
+	report = g.DeleteVms(gogootest.ProjectId, refs, gce.WithConcurrency(5))
+	assert.Nil(t, report.Err())
+	server.SetDeletionPolls(0)
+	for _, ref := range refs {
+		_, err := g.GetVm(gogootest.ProjectId, ref.Zone, ref.Name)
+		assert.True(t, gerror.IsNotFound(err))
+	}
+	vms, _ := g.ListVms(gogootest.ProjectId, fakeZone)
+	assert.Equal(t, 1, len(vms.Items))
+}
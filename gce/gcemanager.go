@@ -2,47 +2,61 @@
 package gce
 
 import (
-	"bytes"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"sort"
+	"net/http"
 	"strings"
-	"text/template"
 	"time"
 
-	"github.com/browny/gogoo/utility"
+	"github.com/browny/gogoo/auth"
+	"github.com/browny/gogoo/gerror"
 
 	log "github.com/cihub/seelog"
+	"golang.org/x/net/context"
 	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
-	"golang.org/x/oauth2/jwt"
 	compute "google.golang.org/api/compute/v1"
 )
 
-func gceError(errMessage string) error {
-	return fmt.Errorf("GCE operation fails: %s", errMessage)
+// gceError wraps the cause into *gerror.Error, the error of a failed operation is classified by its code
+func gceError(operation string, err error) error {
+	result := gerror.New("gce", operation, err)
+
+	var opErr *OperationError
+	if e, ok := result.(*gerror.Error); ok && errors.As(err, &opErr) {
+		e.StatusCode = int(opErr.Operation.HttpErrorStatusCode)
+		e.Reason = opErr.Errors[0].Code
+	}
+
+	return result
 }
 
 const (
 	VmRunningTimeout    = 180 * time.Second
 	VmStoppingTimeout   = 180 * time.Second
 	DiskCreationTimeout = 180 * time.Second
+	DeletionTimeout     = 180 * time.Second
 )
 
 type VmConditionChecker func(projectId, zone, instanceName string) (bool, error)
 
+// Scopes lists the oauth2 scopes required by GceManager
+var Scopes = []string{
+	compute.ComputeScope,
+}
+
 // BuildGceService builds the singlton service for GceManager
 func BuildGceService(serviceEmail string, key []byte) (*compute.Service, error) {
-	conf := &jwt.Config{
-		Email:      serviceEmail,
-		PrivateKey: key,
-		Scopes: []string{
-			compute.ComputeScope,
-		},
-		TokenURL: google.JWTTokenURL,
+	ts, err := auth.PemKey(serviceEmail, key).TokenSource(oauth2.NoContext, Scopes...)
+	if err != nil {
+		return nil, err
 	}
 
-	if service, err := compute.New(conf.Client(oauth2.NoContext)); err != nil {
+	return BuildGceServiceWithClient(oauth2.NewClient(oauth2.NoContext, ts))
+}
+
+// BuildGceServiceWithClient builds the service for GceManager upon an authorized http client
+func BuildGceServiceWithClient(client *http.Client) (*compute.Service, error) {
+	if service, err := compute.New(client); err != nil {
 		return nil, err
 	} else {
 		return service, nil
@@ -56,29 +70,239 @@ func (a BySnapshotName) Len() int           { return len(a) }
 func (a BySnapshotName) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a BySnapshotName) Less(i, j int) bool { return a[i].Name < a[j].Name }
 
+//go:generate mockery -name=Compute
+
+// Compute is the method set of GceManager, it is for consumers to mock GceManager.
+type Compute interface {
+	NewVm(projectId, zone string, vm *compute.Instance) error
+	NewVmContext(ctx context.Context, projectId, zone string, vm *compute.Instance) error
+	GetVm(projectId, zone, vmName string) (*compute.Instance, error)
+	GetVmContext(ctx context.Context, projectId, zone, vmName string) (*compute.Instance, error)
+	DeleteVm(projectId, zone, vmName string, opts ...OperationOption) error
+	DeleteVmContext(ctx context.Context, projectId, zone, vmName string, opts ...OperationOption) error
+	StopVm(projectId, zone, vmName string, vcc VmConditionChecker, opts ...OperationOption) (*compute.Operation, error)
+	StopVmContext(ctx context.Context, projectId, zone, vmName string, vcc VmConditionChecker, opts ...OperationOption) (*compute.Operation, error)
+	SetMachineType(projectId, zone, vmName, machineType string, opts ...OperationOption) (*compute.Operation, error)
+	SetMachineTypeContext(ctx context.Context, projectId, zone, vmName, machineType string, opts ...OperationOption) (*compute.Operation, error)
+	ResetInstance(projectId, zone, vmName string, opts ...OperationOption) (*compute.Operation, error)
+	ResetInstanceContext(ctx context.Context, projectId, zone, vmName string, opts ...OperationOption) (*compute.Operation, error)
+	StartVm(projectId, zone, vmName string, vcc VmConditionChecker, opts ...OperationOption) (*compute.Operation, error)
+	StartVmContext(ctx context.Context, projectId, zone, vmName string, vcc VmConditionChecker, opts ...OperationOption) (*compute.Operation, error)
+	NewVms(projectId string, specs []VmSpec, opts ...FleetOption) *FleetReport
+	NewVmsContext(ctx context.Context, projectId string, specs []VmSpec, opts ...FleetOption) *FleetReport
+	StartVms(projectId string, vms []VmRef, opts ...FleetOption) *FleetReport
+	StartVmsContext(ctx context.Context, projectId string, vms []VmRef, opts ...FleetOption) *FleetReport
+	StopVms(projectId string, vms []VmRef, opts ...FleetOption) *FleetReport
+	StopVmsContext(ctx context.Context, projectId string, vms []VmRef, opts ...FleetOption) *FleetReport
+	DeleteVms(projectId string, vms []VmRef, opts ...FleetOption) *FleetReport
+	DeleteVmsContext(ctx context.Context, projectId string, vms []VmRef, opts ...FleetOption) *FleetReport
+	ListVms(projectId, zone string, opts ...ListOption) (*compute.InstanceList, error)
+	ListVmsContext(ctx context.Context, projectId, zone string, opts ...ListOption) (*compute.InstanceList, error)
+	ListImages(projectId string, opts ...ListOption) (*compute.ImageList, error)
+	ListImagesContext(ctx context.Context, projectId string, opts ...ListOption) (*compute.ImageList, error)
+	ListDisks(projectId, zone string, opts ...ListOption) (*compute.DiskList, error)
+	ListDisksContext(ctx context.Context, projectId, zone string, opts ...ListOption) (*compute.DiskList, error)
+	ListAllVms(projectId string, opts ...ListOption) (map[string][]*compute.Instance, error)
+	ListAllVmsContext(ctx context.Context, projectId string, opts ...ListOption) (map[string][]*compute.Instance, error)
+	ListAllDisks(projectId string, opts ...ListOption) (map[string][]*compute.Disk, error)
+	ListAllDisksContext(ctx context.Context, projectId string, opts ...ListOption) (map[string][]*compute.Disk, error)
+	FindVmZone(projectId, vmName string) (string, error)
+	FindVmZoneContext(ctx context.Context, projectId, vmName string) (string, error)
+	IterateVms(ctx context.Context, projectId, zone string, opts ...ListOption) *VmIterator
+	IterateImages(ctx context.Context, projectId string, opts ...ListOption) *ImageIterator
+	IterateDisks(ctx context.Context, projectId, zone string, opts ...ListOption) *DiskIterator
+	IterateSnapshots(ctx context.Context, projectId string, opts ...ListOption) *SnapshotIterator
+	NewDisk(projectId, zone, name, sourceSnapshot string, sizeGb int64) error
+	NewDiskContext(ctx context.Context, projectId, zone, name, sourceSnapshot string, sizeGb int64) error
+	CreateDisk(projectId, zone, name string, opts ...DiskOption) (*compute.Disk, error)
+	CreateDiskContext(ctx context.Context, projectId, zone, name string, opts ...DiskOption) (*compute.Disk, error)
+	AttachDisk(projectId, zone, vmName string, attached *compute.AttachedDisk) error
+	AttachDiskContext(ctx context.Context, projectId, zone, vmName string, attached *compute.AttachedDisk) error
+	DetachDisk(projectId, zone, vmName, diskName string) error
+	DetachDiskContext(ctx context.Context, projectId, zone, vmName, diskName string) error
+	ResizeDisk(projectId, zone, diskName string, sizeGb int64) error
+	ResizeDiskContext(ctx context.Context, projectId, zone, diskName string, sizeGb int64) error
+	GetDisk(projectId, zone, diskName string) (*compute.Disk, error)
+	GetDiskContext(ctx context.Context, projectId, zone, diskName string) (*compute.Disk, error)
+	DeleteDisk(projectId, zone, diskName string, opts ...OperationOption) error
+	DeleteDiskContext(ctx context.Context, projectId, zone, diskName string, opts ...OperationOption) error
+	GetSnapshots(projectId string, opts ...ListOption) ([]*compute.Snapshot, error)
+	GetSnapshotsContext(ctx context.Context, projectId string, opts ...ListOption) ([]*compute.Snapshot, error)
+	GetSnapshot(projectId, snapshot string) (*compute.Snapshot, error)
+	GetSnapshotContext(ctx context.Context, projectId, snapshot string) (*compute.Snapshot, error)
+	CreateSnapshot(projectId, zone, diskName string, snapshot *compute.Snapshot) (*compute.Snapshot, error)
+	CreateSnapshotContext(ctx context.Context, projectId, zone, diskName string, snapshot *compute.Snapshot) (*compute.Snapshot, error)
+	DeleteSnapshot(projectId, snapshotName string, opts ...OperationOption) error
+	DeleteSnapshotContext(ctx context.Context, projectId, snapshotName string, opts ...OperationOption) error
+	ApplyRetention(projectId, prefix string, policy RetentionPolicy) (*RetentionReport, error)
+	ApplyRetentionContext(ctx context.Context, projectId, prefix string, policy RetentionPolicy) (*RetentionReport, error)
+	AttachTags(projectId, zone, vmName string, addedTags []string, opts ...OperationOption) (*compute.Operation, error)
+	AttachTagsContext(ctx context.Context, projectId, zone, vmName string, addedTags []string, opts ...OperationOption) (*compute.Operation, error)
+	DetachTags(projectId, zone, vmName string, removedTages []string, opts ...OperationOption) (*compute.Operation, error)
+	DetachTagsContext(ctx context.Context, projectId, zone, vmName string, removedTages []string, opts ...OperationOption) (*compute.Operation, error)
+	ReplaceTags(projectId, zone, vmName string, tags []string, opts ...OperationOption) (*compute.Operation, error)
+	ReplaceTagsContext(ctx context.Context, projectId, zone, vmName string, tags []string, opts ...OperationOption) (*compute.Operation, error)
+	SetTagsOnMany(projectId string, vms []VmRef, change TagChange, opts ...FleetOption) *FleetReport
+	SetTagsOnManyContext(ctx context.Context, projectId string, vms []VmRef, change TagChange, opts ...FleetOption) *FleetReport
+	GetLabels(projectId, zone, vmName string) (map[string]string, error)
+	GetLabelsContext(ctx context.Context, projectId, zone, vmName string) (map[string]string, error)
+	SetLabels(projectId, zone, vmName string, labels map[string]string) error
+	SetLabelsContext(ctx context.Context, projectId, zone, vmName string, labels map[string]string) error
+	MergeLabels(projectId, zone, vmName string, labels map[string]string) error
+	MergeLabelsContext(ctx context.Context, projectId, zone, vmName string, labels map[string]string) error
+	RemoveLabels(projectId, zone, vmName string, keys []string) error
+	RemoveLabelsContext(ctx context.Context, projectId, zone, vmName string, keys []string) error
+	GetMetadata(projectId, zone, vmName string) (map[string]string, error)
+	GetMetadataContext(ctx context.Context, projectId, zone, vmName string) (map[string]string, error)
+	SetMetadata(projectId, zone, vmName string, items map[string]string) error
+	SetMetadataContext(ctx context.Context, projectId, zone, vmName string, items map[string]string) error
+	MergeMetadata(projectId, zone, vmName string, items map[string]string) error
+	MergeMetadataContext(ctx context.Context, projectId, zone, vmName string, items map[string]string) error
+	RemoveMetadata(projectId, zone, vmName string, keys []string) error
+	RemoveMetadataContext(ctx context.Context, projectId, zone, vmName string, keys []string) error
+	SetStartupScript(projectId, zone, vmName, script string) error
+	SetStartupScriptContext(ctx context.Context, projectId, zone, vmName, script string) error
+	AddSshKeys(projectId, zone, vmName string, keys []string) error
+	AddSshKeysContext(ctx context.Context, projectId, zone, vmName string, keys []string) error
+	AddInstancesIntoInstanceGroup(projectId, zone, instanceGroupName string, instances []string, opts ...OperationOption) (*compute.Operation, error)
+	AddInstancesIntoInstanceGroupContext(ctx context.Context, projectId, zone, instanceGroupName string, instances []string, opts ...OperationOption) (*compute.Operation, error)
+	RemoveInstancesFromInstanceGroup(projectId, zone, instanceGroupName string, instances []string, opts ...OperationOption) (*compute.Operation, error)
+	RemoveInstancesFromInstanceGroupContext(ctx context.Context, projectId, zone, instanceGroupName string, instances []string, opts ...OperationOption) (*compute.Operation, error)
+	GetInstanceGroup(projectId, zone, instanceGroupName string) (*compute.InstanceGroup, error)
+	GetInstanceGroupContext(ctx context.Context, projectId, zone, instanceGroupName string) (*compute.InstanceGroup, error)
+	CreateInstanceGroup(projectId, zone string, group *compute.InstanceGroup) (*compute.InstanceGroup, error)
+	CreateInstanceGroupContext(ctx context.Context, projectId, zone string, group *compute.InstanceGroup) (*compute.InstanceGroup, error)
+	DeleteInstanceGroup(projectId, zone, instanceGroupName string, opts ...OperationOption) (*compute.Operation, error)
+	DeleteInstanceGroupContext(ctx context.Context, projectId, zone, instanceGroupName string, opts ...OperationOption) (*compute.Operation, error)
+	ListInstanceGroupMembers(projectId, zone, instanceGroupName string) ([]*compute.InstanceWithNamedPorts, error)
+	ListInstanceGroupMembersContext(ctx context.Context, projectId, zone, instanceGroupName string) ([]*compute.InstanceWithNamedPorts, error)
+	SetNamedPorts(projectId, zone, instanceGroupName string, ports []*compute.NamedPort) error
+	SetNamedPortsContext(ctx context.Context, projectId, zone, instanceGroupName string, ports []*compute.NamedPort) error
+	SyncInstanceGroup(projectId, zone, instanceGroupName string, desired []string) (*InstanceGroupDiff, error)
+	SyncInstanceGroupContext(ctx context.Context, projectId, zone, instanceGroupName string, desired []string) (*InstanceGroupDiff, error)
+	GetInstanceTemplate(projectId, name string) (*compute.InstanceTemplate, error)
+	GetInstanceTemplateContext(ctx context.Context, projectId, name string) (*compute.InstanceTemplate, error)
+	CreateInstanceTemplate(projectId, name string, vm *compute.Instance) (*compute.InstanceTemplate, error)
+	CreateInstanceTemplateContext(ctx context.Context, projectId, name string, vm *compute.Instance) (*compute.InstanceTemplate, error)
+	DeleteInstanceTemplate(projectId, name string, opts ...OperationOption) (*compute.Operation, error)
+	DeleteInstanceTemplateContext(ctx context.Context, projectId, name string, opts ...OperationOption) (*compute.Operation, error)
+	GetManagedInstanceGroup(projectId, zone, name string) (*compute.InstanceGroupManager, error)
+	GetManagedInstanceGroupContext(ctx context.Context, projectId, zone, name string) (*compute.InstanceGroupManager, error)
+	CreateManagedInstanceGroup(projectId, zone string, group *compute.InstanceGroupManager) (*compute.InstanceGroupManager, error)
+	CreateManagedInstanceGroupContext(ctx context.Context, projectId, zone string, group *compute.InstanceGroupManager) (*compute.InstanceGroupManager, error)
+	DeleteManagedInstanceGroup(projectId, zone, name string, opts ...OperationOption) (*compute.Operation, error)
+	DeleteManagedInstanceGroupContext(ctx context.Context, projectId, zone, name string, opts ...OperationOption) (*compute.Operation, error)
+	ResizeManagedInstanceGroup(projectId, zone, name string, size int64, opts ...OperationOption) (*compute.Operation, error)
+	ResizeManagedInstanceGroupContext(ctx context.Context, projectId, zone, name string, size int64, opts ...OperationOption) (*compute.Operation, error)
+	RecreateManagedInstances(projectId, zone, name string, instances []string, opts ...OperationOption) (*compute.Operation, error)
+	RecreateManagedInstancesContext(ctx context.Context, projectId, zone, name string, instances []string, opts ...OperationOption) (*compute.Operation, error)
+	AbandonManagedInstances(projectId, zone, name string, instances []string, opts ...OperationOption) (*compute.Operation, error)
+	AbandonManagedInstancesContext(ctx context.Context, projectId, zone, name string, instances []string, opts ...OperationOption) (*compute.Operation, error)
+	ListManagedInstances(projectId, zone, name string) ([]*compute.ManagedInstance, error)
+	ListManagedInstancesContext(ctx context.Context, projectId, zone, name string) ([]*compute.ManagedInstance, error)
+	GetLatestSnapshot(prefix string, snapshots []*compute.Snapshot, opts ...SnapshotOption) (*compute.Snapshot, error)
+	FindLatestSnapshot(projectId, prefix string, opts ...SnapshotOption) (*compute.Snapshot, error)
+	FindLatestSnapshotContext(ctx context.Context, projectId, prefix string, opts ...SnapshotOption) (*compute.Snapshot, error)
+	InitVmFromTemplate(templateFile []byte, zone string) (*compute.Instance, error)
+	InitVmFromTemplateParams(templateFile []byte, params map[string]interface{}) (*compute.Instance, error)
+	ProbeVmRunning(projectId, zone, vmName string, observer chan<- bool)
+	ProbeVmRunningContext(ctx context.Context, projectId, zone, vmName string, observer chan<- bool)
+	ProbeVmStopped(projectId, zone, vmName string, observer chan<- bool)
+	ProbeVmStoppedContext(ctx context.Context, projectId, zone, vmName string, observer chan<- bool)
+	ProbeDiskCreation(projectId, zone, diskName string, observer chan<- bool)
+	ProbeDiskCreationContext(ctx context.Context, projectId, zone, diskName string, observer chan<- bool)
+	NewWatcher(projectId string, opts ...WatcherOption) Watcher
+	GetNatIP(vm *compute.Instance) (string, error)
+	GetNetworkIP(vm *compute.Instance) (string, error)
+	GetNatIPs(vm *compute.Instance) ([]string, error)
+	GetInterfaceAddresses(vm *compute.Instance) ([]*InterfaceAddresses, error)
+	GetAddress(projectId, region, name string) (*compute.Address, error)
+	GetAddressContext(ctx context.Context, projectId, region, name string) (*compute.Address, error)
+	ReserveAddress(projectId, region string, address *compute.Address) (*compute.Address, error)
+	ReserveAddressContext(ctx context.Context, projectId, region string, address *compute.Address) (*compute.Address, error)
+	ReleaseAddress(projectId, region, name string) error
+	ReleaseAddressContext(ctx context.Context, projectId, region, name string) error
+	AddAccessConfig(projectId, zone, vmName, networkInterface string, accessConfig *compute.AccessConfig) error
+	AddAccessConfigContext(ctx context.Context, projectId, zone, vmName, networkInterface string, accessConfig *compute.AccessConfig) error
+	DeleteAccessConfig(projectId, zone, vmName, networkInterface, accessConfigName string) error
+	DeleteAccessConfigContext(ctx context.Context, projectId, zone, vmName, networkInterface, accessConfigName string) error
+	PromoteToStaticIP(projectId, zone, vmName, addressName string) (*compute.Address, error)
+	PromoteToStaticIPContext(ctx context.Context, projectId, zone, vmName, addressName string) (*compute.Address, error)
+	GetSnapshotOfDisk(disk *compute.Disk) string
+	PatchInstanceMachineType(machineType, targetType string) string
+	WaitOperation(ctx context.Context, op *compute.Operation) error
+}
+
+var _ Compute = (*GceManager)(nil)
+
 // GceManager is for low level communication with Google Compute Engine.
+//
+// Each method calling the api has a `Context` variant, which carries the deadline and
+// cancellation of ctx through the api requests and the polling loops.
+// The mutating methods accept `WaitUntilDone()` to block till their operations are DONE.
 type GceManager struct {
 	Service *compute.Service `inject:""`
+
+	// OperationPollInterval is the first interval to poll an operation,
+	// `OperationPollInterval` of the package is used if it is zero.
+	OperationPollInterval time.Duration
+	// StatusPollInterval is the interval to poll the status of a resource, e.g. by ProbeVmRunning and Watcher,
+	// `StatusPollInterval` of the package is used if it is zero.
+	StatusPollInterval time.Duration
+	// Timeouts are the time to wait for the resources to reach a status
+	Timeouts Timeouts
+}
+
+// Timeouts are the time to wait for the resources to reach a status, the timeout of the package,
+// e.g. `VmRunningTimeout`, is used if its field is zero
+type Timeouts struct {
+	VmRunning        time.Duration
+	VmStopping       time.Duration
+	DiskCreation     time.Duration
+	SnapshotCreation time.Duration
+	Deletion         time.Duration
+}
+
+// orDefault returns d, or def if d is not positive
+func orDefault(d, def time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+
+	return def
 }
 
 // NewVm creates a new VM.
-// This method block till the status of created VM is RUNNING
-// or will be timeout if it takes over `VmRunningTimeout`.
+// This method block till the insert operation is DONE and the status of created VM is RUNNING
+// or will be timeout if it takes over `Timeouts.VmRunning`.
 // https://godoc.org/google.golang.org/api/compute/v1#InstancesService.Insert
 func (manager *GceManager) NewVm(projectId, zone string, vm *compute.Instance) error {
+	return manager.NewVmContext(context.Background(), projectId, zone, vm)
+}
+
+// NewVmContext is NewVm which is aborted once ctx is done
+func (manager *GceManager) NewVmContext(ctx context.Context, projectId, zone string, vm *compute.Instance) error {
 	log.Tracef("New VM: project[%s], zone[%s]", projectId, zone)
 
-	if _, err := manager.Service.Instances.Insert(projectId, zone, vm).Do(); err != nil {
-		return gceError(err.Error())
+	op, err := manager.Service.Instances.Insert(projectId, zone, vm).
+		RequestId(newRequestId()).Context(ctx).Do()
+	if err != nil {
+		return gceError("NewVm", err)
+	}
+	if _, err := manager.waitOperation(ctx, op); err != nil {
+		return gceError("NewVm", err)
 	}
 
 	// Pooling the status of the created vm
 	vmRunningObserver := make(chan bool)
-	go manager.ProbeVmRunning(projectId, zone, vm.Name, vmRunningObserver)
+	go manager.ProbeVmRunningContext(ctx, projectId, zone, vm.Name, vmRunningObserver)
 
 	done := <-vmRunningObserver
 	if !done {
-		return gceError(fmt.Sprintf("NewVM timeout: VM[]", vm.Name))
+		if ctx.Err() != nil {
+			return gceError("NewVm", fmt.Errorf("aborted: VM[%s]: %w", vm.Name, ctx.Err()))
+		}
+		return gerror.Errorf("gce", "NewVm", "timeout: VM[%s]", vm.Name)
 	}
 
 	return nil
@@ -87,36 +311,102 @@ func (manager *GceManager) NewVm(projectId, zone string, vm *compute.Instance) e
 // GetVm gets a VM. If VM not existed, return nil.
 // https://godoc.org/google.golang.org/api/compute/v1#InstancesService.Get
 func (manager *GceManager) GetVm(projectId, zone, vmName string) (*compute.Instance, error) {
+	return manager.GetVmContext(context.Background(), projectId, zone, vmName)
+}
+
+// GetVmContext is GetVm with the context of the request
+func (manager *GceManager) GetVmContext(ctx context.Context, projectId, zone, vmName string) (*compute.Instance, error) {
 	log.Tracef("Get VM: project[%s], zone[%s], vmName[%s]", projectId, zone, vmName)
 
-	if vm, err := manager.Service.Instances.Get(projectId, zone, vmName).Do(); err != nil {
-		return nil, gceError(err.Error())
+	if vm, err := manager.Service.Instances.Get(projectId, zone, vmName).Context(ctx).Do(); err != nil {
+		return nil, gceError("GetVm", err)
 	} else {
 		return vm, nil
 	}
 }
 
 // DeleteVm deletes a VM.
+// It blocks till the VM is not found if `WaitUntilDeleted` is given, and deletes the attached
+// non-boot disks as well if `WithAttachedDisks` is given.
 // https://godoc.org/google.golang.org/api/compute/v1#InstancesService.Delete
-func (manager *GceManager) DeleteVm(projectId, zone, vmName string) error {
+func (manager *GceManager) DeleteVm(projectId, zone, vmName string, opts ...OperationOption) error {
+	return manager.DeleteVmContext(context.Background(), projectId, zone, vmName, opts...)
+}
+
+// DeleteVmContext is DeleteVm with the context of the request
+func (manager *GceManager) DeleteVmContext(
+	ctx context.Context, projectId, zone, vmName string, opts ...OperationOption) error {
+
 	log.Tracef("Delete VM: project[%s], zone[%s], vmName[%s]", projectId, zone, vmName)
 
-	if _, err := manager.Service.Instances.Delete(projectId, zone, vmName).Do(); err != nil {
-		return gceError(err.Error())
+	options := manager.newOperationOptions(opts)
+
+	disks := []string{}
+	if options.attachedDisks {
+		vm, err := manager.Service.Instances.Get(projectId, zone, vmName).Context(ctx).Do()
+		if err != nil {
+			return gceError("DeleteVm", err)
+		}
+		for _, disk := range vm.Disks {
+			if !disk.Boot && disk.Source != "" {
+				disks = append(disks, nameOfLink(disk.Source))
+			}
+		}
+	}
+
+	op, err := manager.Service.Instances.Delete(projectId, zone, vmName).Context(ctx).Do()
+	if err != nil {
+		return gceError("DeleteVm", err)
+	}
+	if _, err = manager.finishOperation(ctx, op, opts); err != nil || !options.deleted {
+		return gceError("DeleteVm", err)
+	}
+
+	err = manager.waitStatus(ctx, projectId, KindInstance, zone, vmName, StatusNotFound, options.deletionTimeout)
+	if err != nil {
+		return gceError("DeleteVm", err)
+	}
+
+	// The disks deleted along with the VM by their `autoDelete` are not found
+	for _, disk := range disks {
+		err := manager.DeleteDiskContext(ctx, projectId, zone, disk, WaitUntilDeleted(options.deletionTimeout))
+		if err != nil && !gerror.IsNotFound(err) {
+			return gceError("DeleteVm", fmt.Errorf("delete attached disk[%s]: %w", disk, err))
+		}
 	}
 
 	return nil
 }
 
 // StopVm stops a VM.
-// parameter `vcc` is the checker function to check if the VM is successfully stopped.
+// parameter `vcc` is the checker function to check if the VM is successfully stopped,
+// it is called after the operation is DONE if `WaitUntilDone()` is given, and could be nil.
 // https://godoc.org/google.golang.org/api/compute/v1#InstancesService.Stop
-func (manager *GceManager) StopVm(projectId, zone, vmName string, vcc VmConditionChecker) (*compute.Operation, error) {
+func (manager *GceManager) StopVm(
+	projectId, zone, vmName string, vcc VmConditionChecker, opts ...OperationOption) (*compute.Operation, error) {
+
+	return manager.StopVmContext(context.Background(), projectId, zone, vmName, vcc, opts...)
+}
+
+// StopVmContext is StopVm with the context of the request, `vcc` is not called if ctx is done
+func (manager *GceManager) StopVmContext(
+	ctx context.Context, projectId, zone, vmName string, vcc VmConditionChecker, opts ...OperationOption) (
+	*compute.Operation, error) {
+
 	log.Tracef("Stop instance: project[%s], zone[%s], vmName[%s]", projectId, zone, vmName)
 
-	op, err := manager.Service.Instances.Stop(projectId, zone, vmName).Do()
+	op, err := manager.Service.Instances.Stop(projectId, zone, vmName).Context(ctx).Do()
 	if err != nil {
-		return nil, gceError(err.Error())
+		return nil, gceError("StopVm", err)
+	}
+	if op, err = manager.finishOperation(ctx, op, opts); err != nil {
+		return nil, gceError("StopVm", err)
+	}
+	if vcc == nil {
+		return op, nil
+	}
+	if ctx.Err() != nil {
+		return nil, gceError("StopVm", ctx.Err())
 	}
 
 	if pass, err := vcc(projectId, zone, vmName); pass {
@@ -128,7 +418,17 @@ func (manager *GceManager) StopVm(projectId, zone, vmName string, vcc VmConditio
 
 // SetMachineType changes the machine type for a stopped instance to the machine type specified in the request.
 // https://godoc.org/google.golang.org/api/compute/v1#InstancesService.SetMachineType
-func (manager *GceManager) SetMachineType(projectId, zone, vmName, machineType string) (*compute.Operation, error) {
+func (manager *GceManager) SetMachineType(
+	projectId, zone, vmName, machineType string, opts ...OperationOption) (*compute.Operation, error) {
+
+	return manager.SetMachineTypeContext(context.Background(), projectId, zone, vmName, machineType, opts...)
+}
+
+// SetMachineTypeContext is SetMachineType with the context of the request
+func (manager *GceManager) SetMachineTypeContext(
+	ctx context.Context, projectId, zone, vmName, machineType string, opts ...OperationOption) (
+	*compute.Operation, error) {
+
 	log.Debugf("SetMachineType: project[%s], zone[%s], vmName[%s], type[%s]",
 		projectId, zone, vmName, machineType)
 
@@ -136,26 +436,67 @@ func (manager *GceManager) SetMachineType(projectId, zone, vmName, machineType s
 	machineTypeUri := fmt.Sprintf("zones/%s/machineTypes/%s", zone, machineType)
 	request := compute.InstancesSetMachineTypeRequest{MachineType: machineTypeUri}
 
-	return instanceService.SetMachineType(projectId, zone, vmName, &request).Do()
+	op, err := instanceService.SetMachineType(projectId, zone, vmName, &request).Context(ctx).Do()
+	if err != nil {
+		return nil, gceError("SetMachineType", err)
+	}
+	op, err = manager.finishOperation(ctx, op, opts)
+
+	return op, gceError("SetMachineType", err)
 }
 
 // ResetInstance resets a instance.
 // https://godoc.org/google.golang.org/api/compute/v1#InstancesService.Reset
-func (manager *GceManager) ResetInstance(projectId, zone, vmName string) (*compute.Operation, error) {
+func (manager *GceManager) ResetInstance(
+	projectId, zone, vmName string, opts ...OperationOption) (*compute.Operation, error) {
+
+	return manager.ResetInstanceContext(context.Background(), projectId, zone, vmName, opts...)
+}
+
+// ResetInstanceContext is ResetInstance with the context of the request
+func (manager *GceManager) ResetInstanceContext(
+	ctx context.Context, projectId, zone, vmName string, opts ...OperationOption) (*compute.Operation, error) {
+
 	log.Debugf("Reset instance: project[%s], zone[%s], vmName[%s]", projectId, zone, vmName)
 
-	return manager.Service.Instances.Reset(projectId, zone, vmName).Do()
+	op, err := manager.Service.Instances.Reset(projectId, zone, vmName).Context(ctx).Do()
+	if err != nil {
+		return nil, gceError("ResetInstance", err)
+	}
+	op, err = manager.finishOperation(ctx, op, opts)
+
+	return op, gceError("ResetInstance", err)
 }
 
 // StartVm starts a VM.
-// parameter `vcc` is the checker function to check if the VM is successfully started.
+// parameter `vcc` is the checker function to check if the VM is successfully started,
+// it is called after the operation is DONE if `WaitUntilDone()` is given, and could be nil.
 // https://godoc.org/google.golang.org/api/compute/v1#InstancesService.Start
-func (manager *GceManager) StartVm(projectId, zone, vmName string, vcc VmConditionChecker) (*compute.Operation, error) {
+func (manager *GceManager) StartVm(
+	projectId, zone, vmName string, vcc VmConditionChecker, opts ...OperationOption) (*compute.Operation, error) {
+
+	return manager.StartVmContext(context.Background(), projectId, zone, vmName, vcc, opts...)
+}
+
+// StartVmContext is StartVm with the context of the request, `vcc` is not called if ctx is done
+func (manager *GceManager) StartVmContext(
+	ctx context.Context, projectId, zone, vmName string, vcc VmConditionChecker, opts ...OperationOption) (
+	*compute.Operation, error) {
+
 	log.Tracef("Start instance: project[%s], zone[%s], vmName[%s]", projectId, zone, vmName)
 
-	op, err := manager.Service.Instances.Start(projectId, zone, vmName).Do()
+	op, err := manager.Service.Instances.Start(projectId, zone, vmName).Context(ctx).Do()
 	if err != nil {
-		return nil, gceError(err.Error())
+		return nil, gceError("StartVm", err)
+	}
+	if op, err = manager.finishOperation(ctx, op, opts); err != nil {
+		return nil, gceError("StartVm", err)
+	}
+	if vcc == nil {
+		return op, nil
+	}
+	if ctx.Err() != nil {
+		return nil, gceError("StartVm", ctx.Err())
 	}
 
 	if pass, err := vcc(projectId, zone, vmName); pass {
@@ -165,116 +506,170 @@ func (manager *GceManager) StartVm(projectId, zone, vmName string, vcc VmConditi
 	}
 }
 
-// ListVms lists all VMs.
+// ListVms lists all VMs, all pages are walked and merged into one list.
+// Use `WithFilter` to list only the matched VMs.
 // https://godoc.org/google.golang.org/api/compute/v1#InstancesService.List
-func (manager *GceManager) ListVms(projectId, zone string) (*compute.InstanceList, error) {
+func (manager *GceManager) ListVms(projectId, zone string, opts ...ListOption) (*compute.InstanceList, error) {
+	return manager.ListVmsContext(context.Background(), projectId, zone, opts...)
+}
+
+// ListVmsContext is ListVms with the context of the request
+func (manager *GceManager) ListVmsContext(ctx context.Context, projectId, zone string, opts ...ListOption) (*compute.InstanceList, error) {
 	log.Tracef("List VMs: project[%s], zone[%s]", projectId, zone)
 
-	res, err := manager.Service.Instances.List(projectId, zone).Do()
+	res := &compute.InstanceList{}
+	err := listVmsCall(manager.Service, projectId, zone, opts).Pages(ctx, func(page *compute.InstanceList) error {
+		items := append(res.Items, page.Items...)
+		*res = *page
+		res.Items = items
+		return nil
+	})
 	if err != nil {
-		return nil, gceError(err.Error())
+		return nil, gceError("ListVms", err)
 	}
 
 	return res, nil
 }
 
-// ListImages lists all images.
+// ListImages lists all images, all pages are walked and merged into one list.
 // https://godoc.org/google.golang.org/api/compute/v1#ImagesService.List
-func (manager *GceManager) ListImages(projectId string) (*compute.ImageList, error) {
+func (manager *GceManager) ListImages(projectId string, opts ...ListOption) (*compute.ImageList, error) {
+	return manager.ListImagesContext(context.Background(), projectId, opts...)
+}
+
+// ListImagesContext is ListImages with the context of the request
+func (manager *GceManager) ListImagesContext(ctx context.Context, projectId string, opts ...ListOption) (*compute.ImageList, error) {
 	log.Tracef("List images: project[%s]", projectId)
 
-	res, err := manager.Service.Images.List(projectId).Do()
+	res := &compute.ImageList{}
+	err := listImagesCall(manager.Service, projectId, opts).Pages(ctx, func(page *compute.ImageList) error {
+		items := append(res.Items, page.Items...)
+		*res = *page
+		res.Items = items
+		return nil
+	})
 	if err != nil {
-		return nil, gceError(err.Error())
+		return nil, gceError("ListImages", err)
 	}
 
 	return res, nil
 }
 
-// ListDisks lists all disks.
+// ListDisks lists all disks, all pages are walked and merged into one list.
+// Use `WithFilter` to list only the matched disks.
 // https://godoc.org/google.golang.org/api/compute/v1#DisksService.List
-func (manager *GceManager) ListDisks(projectId, zone string) (*compute.DiskList, error) {
-	log.Tracef("List disks: project[%s], zone[%s]", projectId, zone)
+func (manager *GceManager) ListDisks(projectId, zone string, opts ...ListOption) (*compute.DiskList, error) {
+	return manager.ListDisksContext(context.Background(), projectId, zone, opts...)
+}
 
-	diskService := compute.NewDisksService(manager.Service)
+// ListDisksContext is ListDisks with the context of the request
+func (manager *GceManager) ListDisksContext(ctx context.Context, projectId, zone string, opts ...ListOption) (*compute.DiskList, error) {
+	log.Tracef("List disks: project[%s], zone[%s]", projectId, zone)
 
-	res, err := diskService.List(projectId, zone).Do()
+	res := &compute.DiskList{}
+	err := listDisksCall(manager.Service, projectId, zone, opts).Pages(ctx, func(page *compute.DiskList) error {
+		items := append(res.Items, page.Items...)
+		*res = *page
+		res.Items = items
+		return nil
+	})
 	if err != nil {
-		return nil, gceError(err.Error())
+		return nil, gceError("ListDisks", err)
 	}
 
 	return res, nil
 }
 
-// NewDisk creates a new disk by specified snapshot.
+// NewDisk creates a new disk by specified snapshot, see CreateDisk for the other sources.
+// This method block till the insert operation is DONE and the disk is READY.
 // https://godoc.org/google.golang.org/api/compute/v1#DisksService.Insert
 func (manager *GceManager) NewDisk(projectId, zone, name, sourceSnapshot string, sizeGb int64) error {
+	return manager.NewDiskContext(context.Background(), projectId, zone, name, sourceSnapshot, sizeGb)
+}
+
+// NewDiskContext is NewDisk which is aborted once ctx is done
+func (manager *GceManager) NewDiskContext(
+	ctx context.Context, projectId, zone, name, sourceSnapshot string, sizeGb int64) error {
+
 	log.Tracef("New disk: project[%s], zone[%s], name[%s], sourceSnapshot[%s]",
 		projectId, zone, name, sourceSnapshot)
 
-	diskService := compute.NewDisksService(manager.Service)
-
 	disk := &compute.Disk{
 		Name:           name,
 		SizeGb:         sizeGb,
 		SourceSnapshot: sourceSnapshot}
 
-	if _, err := diskService.Insert(projectId, zone, disk).Do(); err != nil {
-		return gceError(err.Error())
-	}
-
-	diskCreationObserver := make(chan bool)
-	go manager.ProbeDiskCreation(projectId, zone, name, diskCreationObserver)
-
-	done := <-diskCreationObserver
-	if !done {
-		return fmt.Errorf("NewDisk timeout: disk[%s]", name)
-	}
-
-	return nil
+	return gceError("NewDisk", manager.insertDisk(ctx, projectId, zone, disk))
 }
 
 // GetDisk gets disk.
 // https://godoc.org/google.golang.org/api/compute/v1#DisksService.Get
 func (manager *GceManager) GetDisk(projectId, zone, diskName string) (*compute.Disk, error) {
+	return manager.GetDiskContext(context.Background(), projectId, zone, diskName)
+}
+
+// GetDiskContext is GetDisk with the context of the request
+func (manager *GceManager) GetDiskContext(ctx context.Context, projectId, zone, diskName string) (*compute.Disk, error) {
 	log.Tracef("Get disk: project[%s], zone[%s], diskName[%s]", projectId, zone, diskName)
 
 	diskService := compute.NewDisksService(manager.Service)
 
-	disk, err := diskService.Get(projectId, zone, diskName).Do()
+	disk, err := diskService.Get(projectId, zone, diskName).Context(ctx).Do()
 	if err != nil {
-		return nil, gceError(err.Error())
+		return nil, gceError("GetDisk", err)
 	}
 
 	return disk, nil
 }
 
 // DeleteDisk deletes disk.
+// It blocks till the disk is not found if `WaitUntilDeleted` is given.
 // https://godoc.org/google.golang.org/api/compute/v1#DisksService.Delete
-func (manager *GceManager) DeleteDisk(projectId, zone, diskName string) error {
+func (manager *GceManager) DeleteDisk(projectId, zone, diskName string, opts ...OperationOption) error {
+	return manager.DeleteDiskContext(context.Background(), projectId, zone, diskName, opts...)
+}
+
+// DeleteDiskContext is DeleteDisk with the context of the request
+func (manager *GceManager) DeleteDiskContext(
+	ctx context.Context, projectId, zone, diskName string, opts ...OperationOption) error {
+
 	log.Tracef("Delete disk: project[%s], zone[%s], diskName[%s]", projectId, zone, diskName)
 
 	diskService := compute.NewDisksService(manager.Service)
 
-	if _, err := diskService.Delete(projectId, zone, diskName).Do(); err != nil {
-		return gceError(err.Error())
+	op, err := diskService.Delete(projectId, zone, diskName).Context(ctx).Do()
+	if err != nil {
+		return gceError("DeleteDisk", err)
+	}
+	options := manager.newOperationOptions(opts)
+	if _, err = manager.finishOperation(ctx, op, opts); err != nil || !options.deleted {
+		return gceError("DeleteDisk", err)
 	}
 
-	return nil
+	err = manager.waitStatus(ctx, projectId, KindDisk, zone, diskName, StatusNotFound, options.deletionTimeout)
+
+	return gceError("DeleteDisk", err)
 }
 
-// GetSnapshots gets all snapshots of the project.
+// GetSnapshots gets all snapshots of the project, all pages are walked.
 // https://godoc.org/google.golang.org/api/compute/v1#SnapshotsService.List
-func (manager *GceManager) GetSnapshots(projectId string) ([]*compute.Snapshot, error) {
+func (manager *GceManager) GetSnapshots(projectId string, opts ...ListOption) ([]*compute.Snapshot, error) {
+	return manager.GetSnapshotsContext(context.Background(), projectId, opts...)
+}
+
+// GetSnapshotsContext is GetSnapshots with the context of the request
+func (manager *GceManager) GetSnapshotsContext(ctx context.Context, projectId string, opts ...ListOption) ([]*compute.Snapshot, error) {
 	log.Tracef("Get snapshots: project[%s]", projectId)
 
-	snapshotService := compute.NewSnapshotsService(manager.Service)
-	result, err := snapshotService.List(projectId).Do()
+	snapshots := []*compute.Snapshot{}
+	err := listSnapshotsCall(manager.Service, projectId, opts).Pages(ctx, func(page *compute.SnapshotList) error {
+		snapshots = append(snapshots, page.Items...)
+		return nil
+	})
 	if err != nil {
-		return nil, gceError(err.Error())
+		return nil, gceError("GetSnapshots", err)
 	}
 
-	snapshots := result.Items
 	for _, snapshot := range snapshots {
 		log.Tracef("snapshot: id[%d], name[%s]", snapshot.Id, snapshot.Name)
 	}
@@ -285,273 +680,208 @@ func (manager *GceManager) GetSnapshots(projectId string) ([]*compute.Snapshot,
 // GetSnapshot gets the specific snapshot
 // https://godoc.org/google.golang.org/api/compute/v1#SnapshotsService.Get
 func (manager *GceManager) GetSnapshot(projectId, snapshot string) (*compute.Snapshot, error) {
+	return manager.GetSnapshotContext(context.Background(), projectId, snapshot)
+}
+
+// GetSnapshotContext is GetSnapshot with the context of the request
+func (manager *GceManager) GetSnapshotContext(ctx context.Context, projectId, snapshot string) (*compute.Snapshot, error) {
 	log.Tracef("Get snapshot: project[%s], snapshot[%s]", projectId, snapshot)
 
 	snapshotService := compute.NewSnapshotsService(manager.Service)
 
-	if result, err := snapshotService.Get(projectId, snapshot).Do(); err != nil {
-		return nil, gceError(err.Error())
+	if result, err := snapshotService.Get(projectId, snapshot).Context(ctx).Do(); err != nil {
+		return nil, gceError("GetSnapshot", err)
 	} else {
 		return result, nil
 	}
 }
 
-// adjustTags adjusts tags of VM.
-// https://godoc.org/google.golang.org/api/compute/v1#InstancesService.SetTags
-func (manager *GceManager) adjustTags(
-	projectId, zone, vmName string, tags []string, newTagsGenerator func([]string, []string) []string) (
-	*compute.Operation, error) {
+// GetLatestSnapshot gets the latest snapshot by `CreationTimestamp` among the snapshots whose names
+// start with prefix, and the ties are broken by name. Only the READY snapshots are selected unless
+// `WithAnyStatus()` is given, and the options narrow down the selection further.
+// Unlike the earlier versions, the prefix is no longer matched anywhere in the name, the empty prefix
+// with `WithNameRegex(".*part.*")` does that, and the snapshots without a valid `CreationTimestamp` are skipped.
+// The error is *gerror.Error with ReasonNotFound wrapping *SnapshotNotFoundError if nothing is selected.
+func (manager *GceManager) GetLatestSnapshot(
+	prefix string, snapshots []*compute.Snapshot, opts ...SnapshotOption) (*compute.Snapshot, error) {
 
-	vm, err := manager.GetVm(projectId, zone, vmName)
+	selector, err := newSnapshotSelector(prefix, opts)
 	if err != nil {
-		return nil, err
-	}
-
-	vm.Tags.Items = newTagsGenerator(vm.Tags.Items, tags)
-
-	op, err := manager.Service.Instances.SetTags(projectId, zone, vmName, vm.Tags).Do()
-	if err != nil {
-		return nil, gceError(err.Error())
+		return nil, gceError("GetLatestSnapshot", err)
 	}
 
-	return op, nil
-}
-
-// AttachTags attaches tags onto VM.
-func (manager *GceManager) AttachTags(projectId, zone, vmName string, addedTags []string) (*compute.Operation, error) {
-	log.Tracef("AttachTags: vm[%s], addedTags[%s]", vmName, addedTags)
-
-	attacher := func(src, new []string) []string {
-		for _, n := range new {
-			src = append(src, n)
+	var latest *compute.Snapshot
+	var latestCreated time.Time
+	for _, snapshot := range snapshots {
+		created, ok := selector.match(snapshot)
+		if !ok {
+			continue
+		}
+		if latest == nil || created.After(latestCreated) ||
+			(created.Equal(latestCreated) && snapshot.Name > latest.Name) {
+			latest, latestCreated = snapshot, created
 		}
-		return src
 	}
 
-	return manager.adjustTags(projectId, zone, vmName, addedTags, attacher)
-}
-
-// DetachTags detaches tags from VM.
-func (manager *GceManager) DetachTags(projectId, zone, vmName string, removedTages []string) (*compute.Operation, error) {
-	log.Tracef("DetachTags: vm[%s], removedTages[%s]", vmName, removedTages)
-
-	detacher := func(src, remove []string) []string {
-		result := []string{}
-		for _, s := range src {
-			if utility.InStringSlice(remove, s) {
-				continue
-			}
-			result = append(result, s)
+	if latest == nil {
+		log.Warnf("No snapshot found: %s", selector)
+		return nil, &gerror.Error{
+			Service:   "gce",
+			Operation: "GetLatestSnapshot",
+			Reason:    gerror.ReasonNotFound,
+			Err:       &SnapshotNotFoundError{Criteria: selector.String()},
 		}
-		return result
 	}
+	log.Tracef("Latest snapshot found: name[%s], created[%s]", latest.Name, latest.CreationTimestamp)
 
-	return manager.adjustTags(projectId, zone, vmName, removedTages, detacher)
+	return latest, nil
 }
 
-// AddInstancesIntoInstanceGroup adds instances into some instance group
-// https://godoc.org/google.golang.org/api/compute/v1#InstanceGroupsService.AddInstances
-func (manager *GceManager) AddInstancesIntoInstanceGroup(
-	projectId, zone, instanceGroupName string, instances []string) (
-	*compute.Operation, error) {
-
-	log.Tracef(
-		"AddInstancesIntoInstanceGroup: project[%s], region[%s], instanceGroupName[%s], instances[%s]",
-		projectId, zone, instanceGroupName, instances)
-
-	instanceGroupService := compute.NewInstanceGroupsService(manager.Service)
-
-	instanceReferences := []*compute.InstanceReference{}
-	for _, instance := range instances {
-		instanceRef := compute.InstanceReference{Instance: instance}
-		instanceReferences = append(instanceReferences, &instanceRef)
-	}
-	request := compute.InstanceGroupsAddInstancesRequest{Instances: instanceReferences}
-
-	op, err := instanceGroupService.AddInstances(projectId, zone, instanceGroupName, &request).Do()
+// InitVmFromTemplate builds the sample VM from the template with the parameter `Zone`, see VmTemplate
+// for the template. The VM is not validated, so the caller can fill the fields after the call.
+// The template referring to other parameters is built by InitVmFromTemplateParams.
+// The error wraps *TemplateError telling the stage and line which fail.
+func (manager *GceManager) InitVmFromTemplate(templateFile []byte, zone string) (*compute.Instance, error) {
+	tmpl, err := ParseVmTemplate("vm", templateFile)
 	if err != nil {
-		return nil, gceError(err.Error())
-	}
-
-	return op, nil
-}
-
-// GetLatestSnapshot gets latest snapshot with specified prefix in its name
-func (manager *GceManager) GetLatestSnapshot(prefix string, snapshots []*compute.Snapshot) (*compute.Snapshot, error) {
-	filteredSnapshots := []*compute.Snapshot{}
-	for _, snapshot := range snapshots {
-		if strings.Contains(snapshot.Name, prefix) {
-			filteredSnapshots = append(filteredSnapshots, snapshot)
-		}
-	}
-
-	sort.Sort(BySnapshotName(filteredSnapshots))
-	if len(filteredSnapshots) < 1 {
-		log.Warn("No snapshot found")
-		return nil, fmt.Errorf("No snapshot found")
+		return nil, gceError("InitVmFromTemplate", err)
 	}
 
-	result := filteredSnapshots[len(filteredSnapshots)-1]
-	log.Tracef("Latest snapshot found: name[%s]", result.Name)
+	vm, err := tmpl.execute(map[string]interface{}{"Zone": zone})
 
-	return result, nil
+	return vm, gceError("InitVmFromTemplate", err)
 }
 
-// InitVmFromTemplate builds the sample VM from template
-func (manager *GceManager) InitVmFromTemplate(templateFile []byte, zone string) (*compute.Instance, error) {
-	type TemplateParameter struct {
-		Zone string
-	}
-	var tp = TemplateParameter{Zone: zone}
+// InitVmFromTemplateParams builds the VM from the template with the parameters, e.g.
+// {"Zone": "asia-east1-b", "Name": "instance-test"}, and validates it by ValidateVm.
+// The error wraps *TemplateError telling the stage and line which fail.
+func (manager *GceManager) InitVmFromTemplateParams(
+	templateFile []byte, params map[string]interface{}) (*compute.Instance, error) {
 
-	tmpl, _ := template.New("test").Parse(string(templateFile[:]))
-	var b bytes.Buffer
-	tmpl.Execute(&b, tp)
+	tmpl, err := ParseVmTemplate("vm", templateFile)
+	if err != nil {
+		return nil, gceError("InitVmFromTemplate", err)
+	}
 
-	var vm compute.Instance
-	err := json.Unmarshal(b.Bytes(), &vm)
+	vm, err := tmpl.Render(params)
 	if err != nil {
-		return nil, err
+		return nil, gceError("InitVmFromTemplate", err)
 	}
-	// :~)
 
-	return &vm, nil
+	return vm, nil
 }
 
 // ProbeVmRunning probes the VM status till its status is RUNNING or timeout
 func (manager *GceManager) ProbeVmRunning(projectId, zone, vmName string, observer chan<- bool) {
-	startTime := time.Now()
-
-	for {
-		if time.Now().Sub(startTime) > VmRunningTimeout {
-			log.Warnf("VM creation Timeout: VM[%s]", vmName)
-			observer <- false
-
-			break
-		}
-
-		createdInstance, err := manager.GetVm(projectId, zone, vmName)
-
-		if err != nil {
-			log.Tracef("VM not yet Existed: VM[%s]", vmName)
-			time.Sleep(10 * time.Second)
-
-			continue
-		}
-
-		if createdInstance.Status != "RUNNING" {
-			log.Tracef("VM not yet Running: VM[%s]", vmName)
-			time.Sleep(10 * time.Second)
+	manager.ProbeVmRunningContext(context.Background(), projectId, zone, vmName, observer)
+}
 
-			continue
-		}
+// ProbeVmRunningContext is ProbeVmRunning which also stops probing once ctx is done
+func (manager *GceManager) ProbeVmRunningContext(
+	ctx context.Context, projectId, zone, vmName string, observer chan<- bool) {
 
-		log.Infof("VM Running!: VM[%s]", vmName)
-		observer <- true
+	err := manager.waitStatus(ctx, projectId, KindInstance, zone, vmName, "RUNNING",
+		orDefault(manager.Timeouts.VmRunning, VmRunningTimeout))
+	if err != nil {
+		log.Warnf("VM creation Timeout: VM[%s], err[%s]", vmName, err)
+		observer <- false
 
-		break
+		return
 	}
+
+	log.Infof("VM Running!: VM[%s]", vmName)
+	observer <- true
 }
 
 // ProbeInstanceStopped probes the instance status till its status is Stopping or timeout
 func (manager *GceManager) ProbeVmStopped(projectId, zone, vmName string, observer chan<- bool) {
-	startTime := time.Now()
-
-	for {
-		if time.Now().Sub(startTime) > VmStoppingTimeout {
-			log.Warnf("VM stop Timeout: VM[%s]", vmName)
-			observer <- false
-
-			break
-		}
-
-		createdInstance, err := manager.GetVm(projectId, zone, vmName)
-
-		if err != nil {
-			log.Tracef("VM not yet Existed: VM[%s]", vmName)
-			time.Sleep(10 * time.Second)
-
-			continue
-		}
+	manager.ProbeVmStoppedContext(context.Background(), projectId, zone, vmName, observer)
+}
 
-		if createdInstance.Status != "TERMINATED" {
-			log.Tracef("VM not yet Stopped: VM[%s]", vmName)
-			time.Sleep(10 * time.Second)
+// ProbeVmStoppedContext is ProbeVmStopped which also stops probing once ctx is done
+func (manager *GceManager) ProbeVmStoppedContext(
+	ctx context.Context, projectId, zone, vmName string, observer chan<- bool) {
 
-			continue
-		}
-
-		log.Infof("VM Stopped!: VM[%s]", vmName)
-		observer <- true
+	err := manager.waitStatus(ctx, projectId, KindInstance, zone, vmName, "TERMINATED",
+		orDefault(manager.Timeouts.VmStopping, VmStoppingTimeout))
+	if err != nil {
+		log.Warnf("VM stop Timeout: VM[%s], err[%s]", vmName, err)
+		observer <- false
 
-		break
+		return
 	}
+
+	log.Infof("VM Stopped!: VM[%s]", vmName)
+	observer <- true
 }
 
 // ProbeDiskCreation probes the disk status till its status is READY or timeout
 func (manager *GceManager) ProbeDiskCreation(projectId, zone, diskName string, observer chan<- bool) {
-	startTime := time.Now()
-
-	for {
-		if time.Now().Sub(startTime) > DiskCreationTimeout {
-			log.Warnf("Disk creation Timeout: disk[%s]", diskName)
-			observer <- false
-
-			break
-		}
-
-		disk, err := manager.GetDisk(projectId, zone, diskName)
-		if err != nil {
-			log.Tracef("Disk not yet Created: name[%s]", diskName)
-			time.Sleep(10 * time.Second)
-
-			continue
-		}
-		if disk.Status != "READY" {
-			log.Tracef("Disk not yet Ready: name[%s]", disk.Name)
-			time.Sleep(5 * time.Second)
+	manager.ProbeDiskCreationContext(context.Background(), projectId, zone, diskName, observer)
+}
 
-			continue
-		}
+// ProbeDiskCreationContext is ProbeDiskCreation which also stops probing once ctx is done
+func (manager *GceManager) ProbeDiskCreationContext(
+	ctx context.Context, projectId, zone, diskName string, observer chan<- bool) {
 
-		log.Infof("Disk Created!: name[%s]", disk.Name)
-		observer <- true
+	err := manager.waitStatus(ctx, projectId, KindDisk, zone, diskName, "READY",
+		orDefault(manager.Timeouts.DiskCreation, DiskCreationTimeout))
+	if err != nil {
+		log.Warnf("Disk creation Timeout: disk[%s], err[%s]", diskName, err)
+		observer <- false
 
-		break
+		return
 	}
+
+	log.Infof("Disk Created!: name[%s]", diskName)
+	observer <- true
 }
 
-// GetNatIP gets NAT IP address from VM
-func (manager *GceManager) GetNatIP(vm *compute.Instance) string {
-	if vm == nil {
-		return "missing"
+// GetNatIP gets the first external IP address of VM, see GetInterfaceAddresses for all addresses.
+// The error has ReasonNotFound if the VM has no external IP.
+func (manager *GceManager) GetNatIP(vm *compute.Instance) (string, error) {
+	natIPs, err := manager.GetNatIPs(vm)
+	if err != nil {
+		return "", gceError("GetNatIP", err)
+	}
+	if len(natIPs) == 0 {
+		return "", &gerror.Error{
+			Service:   "gce",
+			Operation: "GetNatIP",
+			Reason:    gerror.ReasonNotFound,
+			Err:       fmt.Errorf("No external IP: vm[%s]", vm.Name),
+		}
 	}
-	natIP := vm.NetworkInterfaces[0].AccessConfigs[0].NatIP
-
-	log.Tracef("Got NatIP: VM[%s], ip[%s]", vm.Name, natIP)
+	log.Tracef("Got NatIP: VM[%s], ip[%s]", vm.Name, natIPs[0])
 
-	return natIP
+	return natIPs[0], nil
 }
 
-// GetNetworkIP gets internal IP address from VM
-func (manager *GceManager) GetNetworkIP(vm *compute.Instance) string {
-	if vm == nil {
-		return "missing"
+// GetNetworkIP gets the internal IP address of the first network interface of VM.
+// The error has ReasonNotFound if the VM has no network interface.
+func (manager *GceManager) GetNetworkIP(vm *compute.Instance) (string, error) {
+	addresses, err := manager.GetInterfaceAddresses(vm)
+	if err != nil {
+		return "", gceError("GetNetworkIP", err)
+	}
+	if len(addresses) == 0 || addresses[0].NetworkIP == "" {
+		return "", &gerror.Error{
+			Service:   "gce",
+			Operation: "GetNetworkIP",
+			Reason:    gerror.ReasonNotFound,
+			Err:       fmt.Errorf("No internal IP: vm[%s]", vm.Name),
+		}
 	}
-	networkIP := vm.NetworkInterfaces[0].NetworkIP
-
-	log.Tracef("Got NetworkIP: VM[%s], ip[%s]", vm.Name, networkIP)
+	log.Tracef("Got NetworkIP: VM[%s], ip[%s]", vm.Name, addresses[0].NetworkIP)
 
-	return networkIP
+	return addresses[0].NetworkIP, nil
 }
 
 // GetSnapshotOfDisk gets the snapshot name of the disk
 func (manager *GceManager) GetSnapshotOfDisk(disk *compute.Disk) string {
 	log.Tracef("Snapshot of the disk: snapshot[%s]", disk.SourceSnapshot)
 
-	arr := strings.Split(disk.SourceSnapshot, "/")
-
-	return arr[len(arr)-1]
+	return nameOfLink(disk.SourceSnapshot)
 }
 
 func (manager *GceManager) PatchInstanceMachineType(machineType, targetType string) string {
@@ -12,6 +12,7 @@ import (
 
 	"github.com/browny/gogoo/config"
 	"github.com/browny/gogoo/gce"
+	"github.com/browny/gogoo/gerror"
 	"github.com/browny/gogoo/utility"
 
 	"github.com/facebookgo/inject"
@@ -74,7 +75,7 @@ func (suite *GceManagerTestSuite) Test02_GetVm() {
 
 func (suite *GceManagerTestSuite) Test03_GetNatIP() {
 	instance, _ := testedGceManager.GetVm(testedProjectId, testedZone, "instance-test")
-	natIP := testedGceManager.GetNatIP(instance)
+	natIP, _ := testedGceManager.GetNatIP(instance)
 
 	log.Printf("NatIP: %s", natIP)
 }
@@ -135,16 +136,28 @@ func (suite *GceManagerTestSuite) Test09_GetSnapshots() {
 func (suite *GceManagerTestSuite) Test10_GetLatestSnapshot() {
 	testedSnapshots := []*compute.Snapshot{
 		&compute.Snapshot{
-			Name: "zebra-rtc-alpha-snapshot-201503032021"},
+			Name: "zebra-rtc-alpha-snapshot-201503032021", Status: "READY",
+			CreationTimestamp: "2015-03-03T20:21:00.000-08:00"},
 		&compute.Snapshot{
-			Name: "zebra-rtc-alpha-snapshot-201502161516"},
+			Name: "zebra-rtc-alpha-snapshot-201502161516", Status: "READY",
+			CreationTimestamp: "2015-02-16T15:16:00.000-08:00"},
 		&compute.Snapshot{
-			Name: "zebra-rtc-alpha-snapshot-201502131103"},
+			Name: "zebra-rtc-alpha-snapshot-latest", Status: "READY",
+			CreationTimestamp: "2015-03-04T09:00:00.000-08:00"},
+		&compute.Snapshot{
+			Name: "zebra-rtc-alpha-snapshot-creating", Status: "CREATING",
+			CreationTimestamp: "2015-03-05T09:00:00.000-08:00"},
 	}
 
-	latestSnapshot, _ := testedGceManager.GetLatestSnapshot("alpha", testedSnapshots)
+	latestSnapshot, _ := testedGceManager.GetLatestSnapshot("zebra-rtc-alpha", testedSnapshots)
+	assert.Equal(suite.T(), "zebra-rtc-alpha-snapshot-latest", latestSnapshot.Name)
 
+	latestSnapshot, _ = testedGceManager.GetLatestSnapshot(
+		"zebra-rtc-alpha", testedSnapshots, gce.WithNameRegex(`.*-\d{12}`))
 	assert.Equal(suite.T(), "zebra-rtc-alpha-snapshot-201503032021", latestSnapshot.Name)
+
+	_, err := testedGceManager.GetLatestSnapshot("alpha", testedSnapshots)
+	assert.True(suite.T(), gerror.IsNotFound(err))
 }
 
 func (suite *GceManagerTestSuite) Test11_StopVm() {
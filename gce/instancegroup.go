@@ -0,0 +1,300 @@
+package gce
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/browny/gogoo/gerror"
+	"github.com/browny/gogoo/utility"
+
+	log "github.com/cihub/seelog"
+	"golang.org/x/net/context"
+	compute "google.golang.org/api/compute/v1"
+)
+
+// InstanceGroupDiff is the change of the members of an unmanaged instance group made by
+// SyncInstanceGroup, by the names of the instances
+type InstanceGroupDiff struct {
+	Added   []string
+	Removed []string
+}
+
+// GetInstanceGroup gets the unmanaged instance group
+// https://godoc.org/google.golang.org/api/compute/v1#InstanceGroupsService.Get
+func (manager *GceManager) GetInstanceGroup(projectId, zone, instanceGroupName string) (*compute.InstanceGroup, error) {
+	return manager.GetInstanceGroupContext(context.Background(), projectId, zone, instanceGroupName)
+}
+
+// GetInstanceGroupContext is GetInstanceGroup with the context of the request
+func (manager *GceManager) GetInstanceGroupContext(
+	ctx context.Context, projectId, zone, instanceGroupName string) (*compute.InstanceGroup, error) {
+
+	group, err := manager.Service.InstanceGroups.Get(projectId, zone, instanceGroupName).Context(ctx).Do()
+	if err != nil {
+		return nil, gceError("GetInstanceGroup", err)
+	}
+
+	return group, nil
+}
+
+// CreateInstanceGroup creates the unmanaged instance group, and blocks till the operation is DONE.
+// The network of the group is the one of its first instance if it is not given.
+// https://godoc.org/google.golang.org/api/compute/v1#InstanceGroupsService.Insert
+func (manager *GceManager) CreateInstanceGroup(
+	projectId, zone string, group *compute.InstanceGroup) (*compute.InstanceGroup, error) {
+
+	return manager.CreateInstanceGroupContext(context.Background(), projectId, zone, group)
+}
+
+// CreateInstanceGroupContext is CreateInstanceGroup with the context of the requests
+func (manager *GceManager) CreateInstanceGroupContext(
+	ctx context.Context, projectId, zone string, group *compute.InstanceGroup) (*compute.InstanceGroup, error) {
+
+	log.Tracef("Create instance group: project[%s], zone[%s], name[%s]", projectId, zone, group.Name)
+
+	op, err := manager.Service.InstanceGroups.Insert(projectId, zone, group).
+		RequestId(newRequestId()).Context(ctx).Do()
+	if err != nil {
+		return nil, gceError("CreateInstanceGroup", err)
+	}
+	if _, err := manager.waitOperation(ctx, op); err != nil {
+		return nil, gceError("CreateInstanceGroup", err)
+	}
+
+	created, err := manager.Service.InstanceGroups.Get(projectId, zone, group.Name).Context(ctx).Do()
+
+	return created, gceError("CreateInstanceGroup", err)
+}
+
+// DeleteInstanceGroup deletes the unmanaged instance group, its instances are not deleted.
+// The group used by a backend service can not be deleted.
+// https://godoc.org/google.golang.org/api/compute/v1#InstanceGroupsService.Delete
+func (manager *GceManager) DeleteInstanceGroup(
+	projectId, zone, instanceGroupName string, opts ...OperationOption) (*compute.Operation, error) {
+
+	return manager.DeleteInstanceGroupContext(context.Background(), projectId, zone, instanceGroupName, opts...)
+}
+
+// DeleteInstanceGroupContext is DeleteInstanceGroup with the context of the requests
+func (manager *GceManager) DeleteInstanceGroupContext(
+	ctx context.Context, projectId, zone, instanceGroupName string, opts ...OperationOption) (
+	*compute.Operation, error) {
+
+	log.Tracef("Delete instance group: project[%s], zone[%s], name[%s]", projectId, zone, instanceGroupName)
+
+	op, err := manager.Service.InstanceGroups.Delete(projectId, zone, instanceGroupName).Context(ctx).Do()
+	if err != nil {
+		return nil, gceError("DeleteInstanceGroup", err)
+	}
+	op, err = manager.finishOperation(ctx, op, opts)
+
+	return op, gceError("DeleteInstanceGroup", err)
+}
+
+// AddInstancesIntoInstanceGroup adds instances into some instance group, the instances are
+// the names or the urls of the instances in the zone of the group
+// https://godoc.org/google.golang.org/api/compute/v1#InstanceGroupsService.AddInstances
+func (manager *GceManager) AddInstancesIntoInstanceGroup(
+	projectId, zone, instanceGroupName string, instances []string, opts ...OperationOption) (
+	*compute.Operation, error) {
+
+	return manager.AddInstancesIntoInstanceGroupContext(
+		context.Background(), projectId, zone, instanceGroupName, instances, opts...)
+}
+
+// AddInstancesIntoInstanceGroupContext is AddInstancesIntoInstanceGroup with the context of the request
+func (manager *GceManager) AddInstancesIntoInstanceGroupContext(
+	ctx context.Context, projectId, zone, instanceGroupName string, instances []string, opts ...OperationOption) (
+	*compute.Operation, error) {
+
+	log.Tracef(
+		"AddInstancesIntoInstanceGroup: project[%s], region[%s], instanceGroupName[%s], instances[%s]",
+		projectId, zone, instanceGroupName, instances)
+
+	instanceGroupService := compute.NewInstanceGroupsService(manager.Service)
+
+	request := compute.InstanceGroupsAddInstancesRequest{
+		Instances: instanceReferences(projectId, zone, instances),
+	}
+
+	op, err := instanceGroupService.AddInstances(projectId, zone, instanceGroupName, &request).Context(ctx).Do()
+	if err != nil {
+		return nil, gceError("AddInstancesIntoInstanceGroup", err)
+	}
+	op, err = manager.finishOperation(ctx, op, opts)
+
+	return op, gceError("AddInstancesIntoInstanceGroup", err)
+}
+
+// RemoveInstancesFromInstanceGroup removes instances from some instance group, the instances are
+// the names or the urls of the instances. The instances are not deleted.
+// https://godoc.org/google.golang.org/api/compute/v1#InstanceGroupsService.RemoveInstances
+func (manager *GceManager) RemoveInstancesFromInstanceGroup(
+	projectId, zone, instanceGroupName string, instances []string, opts ...OperationOption) (
+	*compute.Operation, error) {
+
+	return manager.RemoveInstancesFromInstanceGroupContext(
+		context.Background(), projectId, zone, instanceGroupName, instances, opts...)
+}
+
+// RemoveInstancesFromInstanceGroupContext is RemoveInstancesFromInstanceGroup with the context of the request
+func (manager *GceManager) RemoveInstancesFromInstanceGroupContext(
+	ctx context.Context, projectId, zone, instanceGroupName string, instances []string, opts ...OperationOption) (
+	*compute.Operation, error) {
+
+	log.Tracef(
+		"RemoveInstancesFromInstanceGroup: project[%s], zone[%s], instanceGroupName[%s], instances[%s]",
+		projectId, zone, instanceGroupName, instances)
+
+	request := &compute.InstanceGroupsRemoveInstancesRequest{
+		Instances: instanceReferences(projectId, zone, instances),
+	}
+
+	op, err := manager.Service.InstanceGroups.RemoveInstances(projectId, zone, instanceGroupName, request).
+		Context(ctx).Do()
+	if err != nil {
+		return nil, gceError("RemoveInstancesFromInstanceGroup", err)
+	}
+	op, err = manager.finishOperation(ctx, op, opts)
+
+	return op, gceError("RemoveInstancesFromInstanceGroup", err)
+}
+
+// ListInstanceGroupMembers lists all instances of the instance group with their status, e.g. RUNNING
+// https://godoc.org/google.golang.org/api/compute/v1#InstanceGroupsService.ListInstances
+func (manager *GceManager) ListInstanceGroupMembers(
+	projectId, zone, instanceGroupName string) ([]*compute.InstanceWithNamedPorts, error) {
+
+	return manager.ListInstanceGroupMembersContext(context.Background(), projectId, zone, instanceGroupName)
+}
+
+// ListInstanceGroupMembersContext is ListInstanceGroupMembers with the context of the requests
+func (manager *GceManager) ListInstanceGroupMembersContext(
+	ctx context.Context, projectId, zone, instanceGroupName string) ([]*compute.InstanceWithNamedPorts, error) {
+
+	request := &compute.InstanceGroupsListInstancesRequest{InstanceState: "ALL"}
+
+	members := []*compute.InstanceWithNamedPorts{}
+	err := manager.Service.InstanceGroups.ListInstances(projectId, zone, instanceGroupName, request).
+		Pages(ctx, func(page *compute.InstanceGroupsListInstances) error {
+			members = append(members, page.Items...)
+			return nil
+		})
+	if err != nil {
+		return nil, gceError("ListInstanceGroupMembers", err)
+	}
+
+	return members, nil
+}
+
+// SetNamedPorts replaces the named ports of the instance group, e.g. {Name: "http", Port: 80},
+// which are the ports the backend services send the traffic to. It blocks till the operation is DONE.
+// https://godoc.org/google.golang.org/api/compute/v1#InstanceGroupsService.SetNamedPorts
+func (manager *GceManager) SetNamedPorts(projectId, zone, instanceGroupName string, ports []*compute.NamedPort) error {
+	return manager.SetNamedPortsContext(context.Background(), projectId, zone, instanceGroupName, ports)
+}
+
+// SetNamedPortsContext is SetNamedPorts with the context of the requests
+func (manager *GceManager) SetNamedPortsContext(
+	ctx context.Context, projectId, zone, instanceGroupName string, ports []*compute.NamedPort) error {
+
+	log.Tracef("SetNamedPorts: project[%s], zone[%s], instanceGroupName[%s], count[%d]",
+		projectId, zone, instanceGroupName, len(ports))
+
+	for attempt := 1; ; attempt++ {
+		group, err := manager.Service.InstanceGroups.Get(projectId, zone, instanceGroupName).Context(ctx).Do()
+		if err != nil {
+			return gceError("SetNamedPorts", err)
+		}
+
+		request := &compute.InstanceGroupsSetNamedPortsRequest{NamedPorts: ports, Fingerprint: group.Fingerprint}
+		op, err := manager.Service.InstanceGroups.SetNamedPorts(projectId, zone, instanceGroupName, request).
+			Context(ctx).Do()
+		if gerror.IsPreconditionFailed(err) && attempt < FingerprintMaxAttempts {
+			log.Debugf("Instance group[%s] is changed meanwhile, attempt[%d]", instanceGroupName, attempt)
+			continue
+		}
+		if err != nil {
+			return gceError("SetNamedPorts", err)
+		}
+		_, err = manager.waitOperation(ctx, op)
+
+		return gceError("SetNamedPorts", err)
+	}
+}
+
+// SyncInstanceGroup makes the members of the unmanaged instance group exactly the desired instances,
+// which are the names or the urls of the instances in the zone of the group. The missing instances
+// are added before the others are removed, so the backends keep serving during the sync.
+// It blocks till the operations are DONE, and the diff planned is returned even if it fails to apply.
+func (manager *GceManager) SyncInstanceGroup(
+	projectId, zone, instanceGroupName string, desired []string) (*InstanceGroupDiff, error) {
+
+	return manager.SyncInstanceGroupContext(context.Background(), projectId, zone, instanceGroupName, desired)
+}
+
+// SyncInstanceGroupContext is SyncInstanceGroup with the context of the requests
+func (manager *GceManager) SyncInstanceGroupContext(
+	ctx context.Context, projectId, zone, instanceGroupName string, desired []string) (*InstanceGroupDiff, error) {
+
+	members, err := manager.ListInstanceGroupMembersContext(ctx, projectId, zone, instanceGroupName)
+	if err != nil {
+		return nil, gceError("SyncInstanceGroup", err)
+	}
+
+	current := []string{}
+	for _, member := range members {
+		current = append(current, nameOfLink(member.Instance))
+	}
+	wanted := []string{}
+	for _, instance := range desired {
+		if name := nameOfLink(instance); !utility.InStringSlice(wanted, name) {
+			wanted = append(wanted, name)
+		}
+	}
+
+	diff := &InstanceGroupDiff{Added: []string{}, Removed: []string{}}
+	for _, name := range wanted {
+		if !utility.InStringSlice(current, name) {
+			diff.Added = append(diff.Added, name)
+		}
+	}
+	for _, name := range current {
+		if !utility.InStringSlice(wanted, name) {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+
+	log.Tracef("SyncInstanceGroup: project[%s], zone[%s], instanceGroupName[%s], added[%s], removed[%s]",
+		projectId, zone, instanceGroupName, diff.Added, diff.Removed)
+
+	if len(diff.Added) > 0 {
+		_, err := manager.AddInstancesIntoInstanceGroupContext(
+			ctx, projectId, zone, instanceGroupName, diff.Added, WaitUntilDone())
+		if err != nil {
+			return diff, gceError("SyncInstanceGroup", err)
+		}
+	}
+	if len(diff.Removed) > 0 {
+		_, err := manager.RemoveInstancesFromInstanceGroupContext(
+			ctx, projectId, zone, instanceGroupName, diff.Removed, WaitUntilDone())
+		if err != nil {
+			return diff, gceError("SyncInstanceGroup", err)
+		}
+	}
+
+	return diff, nil
+}
+
+// instanceReferences converts the names or the urls of the instances into the references
+func instanceReferences(projectId, zone string, instances []string) []*compute.InstanceReference {
+	references := []*compute.InstanceReference{}
+	for _, instance := range instances {
+		if !strings.Contains(instance, "/") {
+			instance = fmt.Sprintf("projects/%s/zones/%s/instances/%s", projectId, zone, instance)
+		}
+		references = append(references, &compute.InstanceReference{Instance: instance})
+	}
+
+	return references
+}
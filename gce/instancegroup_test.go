@@ -0,0 +1,91 @@
+package gce_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/browny/gogoo/gce"
+	"github.com/browny/gogoo/gerror"
+	"github.com/browny/gogoo/gogootest"
+
+	"github.com/stretchr/testify/assert"
+	compute "google.golang.org/api/compute/v1"
+)
+
+func TestInstanceGroup(t *testing.T) {
+	server, g := gogootest.NewGoGoo()
+	defer server.Close()
+	server.SetPageSize(2)
+
+	for _, name := range []string{"vm-1", "vm-2", "vm-3"} {
+		server.AddInstance(fakeZone, fakeVm(name))
+	}
+	stopped := fakeVm("vm-4")
+	stopped.Status = "TERMINATED"
+	server.AddInstance(fakeZone, stopped)
+
+	group, err := g.CreateInstanceGroup(gogootest.ProjectId, fakeZone, &compute.InstanceGroup{
+		Name:       "group-test",
+		NamedPorts: []*compute.NamedPort{{Name: "http", Port: 80}},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "group-test", group.Name)
+	assert.Equal(t, int64(0), group.Size)
+
+	_, err = g.AddInstancesIntoInstanceGroup(
+		gogootest.ProjectId, fakeZone, "group-test", []string{"vm-1", "vm-2", "vm-4"}, gce.WaitUntilDone())
+	assert.Nil(t, err)
+	_, err = g.AddInstancesIntoInstanceGroup(
+		gogootest.ProjectId, fakeZone, "group-test", []string{"vm-1"}, gce.WaitUntilDone())
+	assert.NotNil(t, err)
+
+	members, err := g.ListInstanceGroupMembers(gogootest.ProjectId, fakeZone, "group-test")
+	assert.Nil(t, err)
+	assert.Len(t, members, 3)
+	statuses := map[string]string{}
+	for _, member := range members {
+		statuses[member.Instance[strings.LastIndex(member.Instance, "/")+1:]] = member.Status
+		assert.Equal(t, int64(80), member.NamedPorts[0].Port)
+	}
+	assert.Equal(t, map[string]string{"vm-1": "RUNNING", "vm-2": "RUNNING", "vm-4": "TERMINATED"}, statuses)
+
+	_, err = g.RemoveInstancesFromInstanceGroup(
+		gogootest.ProjectId, fakeZone, "group-test", []string{"vm-4"}, gce.WaitUntilDone())
+	assert.Nil(t, err)
+	group, _ = g.GetInstanceGroup(gogootest.ProjectId, fakeZone, "group-test")
+	assert.Equal(t, int64(2), group.Size)
+
+	// Named ports
+	ports := []*compute.NamedPort{{Name: "http", Port: 8080}, {Name: "https", Port: 8443}}
+	assert.Nil(t, g.SetNamedPorts(gogootest.ProjectId, fakeZone, "group-test", ports))
+	group, _ = g.GetInstanceGroup(gogootest.ProjectId, fakeZone, "group-test")
+	assert.Equal(t, ports, group.NamedPorts)
+
+	// Sync adds the missing instances and removes the others
+	diff, err := g.SyncInstanceGroup(gogootest.ProjectId, fakeZone, "group-test", []string{"vm-2", "vm-3", "vm-3"})
+	assert.Nil(t, err)
+	assert.Equal(t, &gce.InstanceGroupDiff{Added: []string{"vm-3"}, Removed: []string{"vm-1"}}, diff)
+	members, _ = g.ListInstanceGroupMembers(gogootest.ProjectId, fakeZone, "group-test")
+	assert.Len(t, members, 2)
+
+	diff, err = g.SyncInstanceGroup(gogootest.ProjectId, fakeZone, "group-test", []string{"vm-2", "vm-3"})
+	assert.Nil(t, err)
+	assert.Empty(t, diff.Added)
+	assert.Empty(t, diff.Removed)
+
+	diff, err = g.SyncInstanceGroup(gogootest.ProjectId, fakeZone, "group-test", []string{"vm-2", "vm-5"})
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"vm-5"}, diff.Added)
+
+	// The deleted instance leaves the group
+	assert.Nil(t, g.DeleteVm(gogootest.ProjectId, fakeZone, "vm-3"))
+	members, _ = g.ListInstanceGroupMembers(gogootest.ProjectId, fakeZone, "group-test")
+	assert.Len(t, members, 1)
+
+	_, err = g.DeleteInstanceGroup(gogootest.ProjectId, fakeZone, "group-test", gce.WaitUntilDone())
+	assert.Nil(t, err)
+	_, err = g.GetInstanceGroup(gogootest.ProjectId, fakeZone, "group-test")
+	assert.True(t, gerror.IsNotFound(err))
+	_, err = g.GetVm(gogootest.ProjectId, fakeZone, "vm-1")
+	assert.Nil(t, err)
+}
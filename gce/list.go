@@ -0,0 +1,283 @@
+package gce
+
+import (
+	log "github.com/cihub/seelog"
+	"golang.org/x/net/context"
+	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/iterator"
+)
+
+// pager keeps the page token of an iterator, it fetches the pages one by one on demand
+type pager struct {
+	ctx       context.Context
+	operation string
+	token     string
+	done      bool
+	err       error
+}
+
+// nextPage fetches the next page by fetch, which returns the token of the page after it.
+// It returns iterator.Done after the last page, and the error of a failed fetch afterwards.
+func (p *pager) nextPage(fetch func(token string) (string, error)) error {
+	if p.err != nil {
+		return p.err
+	}
+	if p.done {
+		return iterator.Done
+	}
+
+	next, err := fetch(p.token)
+	if err != nil {
+		p.err = gceError(p.operation, err)
+		return p.err
+	}
+	p.token = next
+	p.done = next == ""
+
+	return nil
+}
+
+// VmIterator iterates the VMs page by page, only the current page is held in memory
+type VmIterator struct {
+	pager
+	call  *compute.InstancesListCall
+	items []*compute.Instance
+}
+
+// NewVmIterator returns the iterator of the given VMs, which needs no api call.
+// It is for the mocks and fakes of Compute.
+func NewVmIterator(items []*compute.Instance) *VmIterator {
+	return &VmIterator{pager: pager{done: true}, items: items}
+}
+
+// Next returns the next VM, or iterator.Done if there is no more VM
+func (it *VmIterator) Next() (*compute.Instance, error) {
+	for len(it.items) == 0 {
+		err := it.nextPage(func(token string) (string, error) {
+			res, err := it.call.PageToken(token).Context(it.ctx).Do()
+			if err != nil {
+				return "", err
+			}
+			it.items = res.Items
+
+			return res.NextPageToken, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	vm := it.items[0]
+	it.items = it.items[1:]
+
+	return vm, nil
+}
+
+// ImageIterator iterates the images page by page, only the current page is held in memory
+type ImageIterator struct {
+	pager
+	call  *compute.ImagesListCall
+	items []*compute.Image
+}
+
+// NewImageIterator returns the iterator of the given images, which needs no api call.
+// It is for the mocks and fakes of Compute.
+func NewImageIterator(items []*compute.Image) *ImageIterator {
+	return &ImageIterator{pager: pager{done: true}, items: items}
+}
+
+// Next returns the next image, or iterator.Done if there is no more image
+func (it *ImageIterator) Next() (*compute.Image, error) {
+	for len(it.items) == 0 {
+		err := it.nextPage(func(token string) (string, error) {
+			res, err := it.call.PageToken(token).Context(it.ctx).Do()
+			if err != nil {
+				return "", err
+			}
+			it.items = res.Items
+
+			return res.NextPageToken, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	image := it.items[0]
+	it.items = it.items[1:]
+
+	return image, nil
+}
+
+// DiskIterator iterates the disks page by page, only the current page is held in memory
+type DiskIterator struct {
+	pager
+	call  *compute.DisksListCall
+	items []*compute.Disk
+}
+
+// NewDiskIterator returns the iterator of the given disks, which needs no api call.
+// It is for the mocks and fakes of Compute.
+func NewDiskIterator(items []*compute.Disk) *DiskIterator {
+	return &DiskIterator{pager: pager{done: true}, items: items}
+}
+
+// Next returns the next disk, or iterator.Done if there is no more disk
+func (it *DiskIterator) Next() (*compute.Disk, error) {
+	for len(it.items) == 0 {
+		err := it.nextPage(func(token string) (string, error) {
+			res, err := it.call.PageToken(token).Context(it.ctx).Do()
+			if err != nil {
+				return "", err
+			}
+			it.items = res.Items
+
+			return res.NextPageToken, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	disk := it.items[0]
+	it.items = it.items[1:]
+
+	return disk, nil
+}
+
+// SnapshotIterator iterates the snapshots page by page, only the current page is held in memory
+type SnapshotIterator struct {
+	pager
+	call  *compute.SnapshotsListCall
+	items []*compute.Snapshot
+}
+
+// NewSnapshotIterator returns the iterator of the given snapshots, which needs no api call.
+// It is for the mocks and fakes of Compute.
+func NewSnapshotIterator(items []*compute.Snapshot) *SnapshotIterator {
+	return &SnapshotIterator{pager: pager{done: true}, items: items}
+}
+
+// Next returns the next snapshot, or iterator.Done if there is no more snapshot
+func (it *SnapshotIterator) Next() (*compute.Snapshot, error) {
+	for len(it.items) == 0 {
+		err := it.nextPage(func(token string) (string, error) {
+			res, err := it.call.PageToken(token).Context(it.ctx).Do()
+			if err != nil {
+				return "", err
+			}
+			it.items = res.Items
+
+			return res.NextPageToken, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	snapshot := it.items[0]
+	it.items = it.items[1:]
+
+	return snapshot, nil
+}
+
+// IterateVms iterates all VMs of the zone, the pages are fetched on demand by `Next`
+//
+//	it := manager.IterateVms(ctx, projectId, zone)
+//	for {
+//		vm, err := it.Next()
+//		if err == iterator.Done {
+//			break
+//		}
+//		...
+//	}
+func (manager *GceManager) IterateVms(ctx context.Context, projectId, zone string, opts ...ListOption) *VmIterator {
+	log.Tracef("Iterate VMs: project[%s], zone[%s]", projectId, zone)
+
+	return &VmIterator{
+		pager: pager{ctx: ctx, operation: "IterateVms"},
+		call:  listVmsCall(manager.Service, projectId, zone, opts),
+	}
+}
+
+// IterateImages iterates all images of the project, the pages are fetched on demand by `Next`
+func (manager *GceManager) IterateImages(ctx context.Context, projectId string, opts ...ListOption) *ImageIterator {
+	log.Tracef("Iterate images: project[%s]", projectId)
+
+	return &ImageIterator{
+		pager: pager{ctx: ctx, operation: "IterateImages"},
+		call:  listImagesCall(manager.Service, projectId, opts),
+	}
+}
+
+// IterateDisks iterates all disks of the zone, the pages are fetched on demand by `Next`
+func (manager *GceManager) IterateDisks(ctx context.Context, projectId, zone string, opts ...ListOption) *DiskIterator {
+	log.Tracef("Iterate disks: project[%s], zone[%s]", projectId, zone)
+
+	return &DiskIterator{
+		pager: pager{ctx: ctx, operation: "IterateDisks"},
+		call:  listDisksCall(manager.Service, projectId, zone, opts),
+	}
+}
+
+// IterateSnapshots iterates all snapshots of the project, the pages are fetched on demand by `Next`
+func (manager *GceManager) IterateSnapshots(ctx context.Context, projectId string, opts ...ListOption) *SnapshotIterator {
+	log.Tracef("Iterate snapshots: project[%s]", projectId)
+
+	return &SnapshotIterator{
+		pager: pager{ctx: ctx, operation: "IterateSnapshots"},
+		call:  listSnapshotsCall(manager.Service, projectId, opts),
+	}
+}
+
+func listVmsCall(service *compute.Service, projectId, zone string, opts []ListOption) *compute.InstancesListCall {
+	options := newListOptions(opts)
+	call := service.Instances.List(projectId, zone)
+	if options.filter != "" {
+		call.Filter(options.filter)
+	}
+	if options.maxResults > 0 {
+		call.MaxResults(options.maxResults)
+	}
+
+	return call
+}
+
+func listImagesCall(service *compute.Service, projectId string, opts []ListOption) *compute.ImagesListCall {
+	options := newListOptions(opts)
+	call := service.Images.List(projectId)
+	if options.filter != "" {
+		call.Filter(options.filter)
+	}
+	if options.maxResults > 0 {
+		call.MaxResults(options.maxResults)
+	}
+
+	return call
+}
+
+func listDisksCall(service *compute.Service, projectId, zone string, opts []ListOption) *compute.DisksListCall {
+	options := newListOptions(opts)
+	call := service.Disks.List(projectId, zone)
+	if options.filter != "" {
+		call.Filter(options.filter)
+	}
+	if options.maxResults > 0 {
+		call.MaxResults(options.maxResults)
+	}
+
+	return call
+}
+
+func listSnapshotsCall(service *compute.Service, projectId string, opts []ListOption) *compute.SnapshotsListCall {
+	options := newListOptions(opts)
+	call := service.Snapshots.List(projectId)
+	if options.filter != "" {
+		call.Filter(options.filter)
+	}
+	if options.maxResults > 0 {
+		call.MaxResults(options.maxResults)
+	}
+
+	return call
+}
@@ -0,0 +1,112 @@
+package gce_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/browny/gogoo/gce"
+	"github.com/browny/gogoo/gogootest"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/iterator"
+)
+
+func TestNewVmIterator(t *testing.T) {
+	it := gce.NewVmIterator([]*compute.Instance{{Name: "vm-1"}, {Name: "vm-2"}})
+
+	names := []string{}
+	for {
+		vm, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		assert.Nil(t, err)
+		names = append(names, vm.Name)
+	}
+	assert.Equal(t, []string{"vm-1", "vm-2"}, names)
+
+	_, err := gce.NewSnapshotIterator(nil).Next()
+	assert.Equal(t, iterator.Done, err)
+}
+
+func TestPagination(t *testing.T) {
+	server, g := gogootest.NewGoGoo()
+	defer server.Close()
+
+	server.SetPageSize(2)
+	for i := 0; i < 5; i++ {
+		server.AddInstance(fakeZone, fakeVm(fmt.Sprintf("instance-%d", i)))
+		server.AddImage(&compute.Image{Name: fmt.Sprintf("image-%d", i)})
+		server.AddDisk(fakeZone, &compute.Disk{Name: fmt.Sprintf("disk-%d", i)})
+		server.AddSnapshot(&compute.Snapshot{Name: fmt.Sprintf("snapshot-%d", i)})
+	}
+
+	vms, err := g.ListVms(gogootest.ProjectId, fakeZone)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(vms.Items))
+	assert.Empty(t, vms.NextPageToken)
+
+	images, err := g.ListImages(gogootest.ProjectId)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(images.Items))
+
+	// The boot disks of the VMs are listed as well
+	disks, err := g.ListDisks(gogootest.ProjectId, fakeZone)
+	assert.Nil(t, err)
+	assert.Equal(t, 10, len(disks.Items))
+
+	snapshots, err := g.GetSnapshots(gogootest.ProjectId)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(snapshots))
+
+	latest, err := g.GetLatestSnapshot("snapshot", snapshots)
+	assert.Nil(t, err)
+	assert.Equal(t, "snapshot-4", latest.Name)
+
+	// Iterators fetch the pages on demand
+	requests := server.Requests()
+	it := g.IterateSnapshots(context.Background(), gogootest.ProjectId)
+	snapshot, err := it.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, "snapshot-0", snapshot.Name)
+	assert.Equal(t, requests+1, server.Requests())
+
+	names := []string{snapshot.Name}
+	for {
+		snapshot, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		assert.Nil(t, err)
+		names = append(names, snapshot.Name)
+	}
+	assert.Equal(t, 5, len(names))
+	assert.Equal(t, requests+3, server.Requests())
+	_, err = it.Next()
+	assert.Equal(t, iterator.Done, err)
+
+	vmIt := g.IterateVms(context.Background(), gogootest.ProjectId, fakeZone)
+	count := 0
+	for _, err := vmIt.Next(); err != iterator.Done; _, err = vmIt.Next() {
+		assert.Nil(t, err)
+		count++
+	}
+	assert.Equal(t, 5, count)
+
+	// Iterator keeps the error
+	server.FailNextRequests(1, http.StatusForbidden, "forbidden")
+	diskIt := g.IterateDisks(context.Background(), gogootest.ProjectId, fakeZone)
+	_, err = diskIt.Next()
+	assert.NotNil(t, err)
+	assert.NotEqual(t, iterator.Done, err)
+	_, err2 := diskIt.Next()
+	assert.Equal(t, err, err2)
+
+	imageIt := g.IterateImages(context.Background(), gogootest.ProjectId)
+	image, err := imageIt.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, "image-0", image.Name)
+}
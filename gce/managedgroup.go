@@ -0,0 +1,372 @@
+package gce
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/cihub/seelog"
+	"golang.org/x/net/context"
+	compute "google.golang.org/api/compute/v1"
+)
+
+// GetInstanceTemplate gets the instance template
+// https://godoc.org/google.golang.org/api/compute/v1#InstanceTemplatesService.Get
+func (manager *GceManager) GetInstanceTemplate(projectId, name string) (*compute.InstanceTemplate, error) {
+	return manager.GetInstanceTemplateContext(context.Background(), projectId, name)
+}
+
+// GetInstanceTemplateContext is GetInstanceTemplate with the context of the request
+func (manager *GceManager) GetInstanceTemplateContext(
+	ctx context.Context, projectId, name string) (*compute.InstanceTemplate, error) {
+
+	template, err := manager.Service.InstanceTemplates.Get(projectId, name).Context(ctx).Do()
+	if err != nil {
+		return nil, gceError("GetInstanceTemplate", err)
+	}
+
+	return template, nil
+}
+
+// CreateInstanceTemplate creates the instance template from the VM spec, e.g. the VM built by
+// InitVmFromTemplate, and blocks till the operation is DONE. The zonal urls of the spec are turned
+// into names, and the addresses of the VM are dropped, so every instance gets its own.
+// https://godoc.org/google.golang.org/api/compute/v1#InstanceTemplatesService.Insert
+func (manager *GceManager) CreateInstanceTemplate(
+	projectId, name string, vm *compute.Instance) (*compute.InstanceTemplate, error) {
+
+	return manager.CreateInstanceTemplateContext(context.Background(), projectId, name, vm)
+}
+
+// CreateInstanceTemplateContext is CreateInstanceTemplate with the context of the requests
+func (manager *GceManager) CreateInstanceTemplateContext(
+	ctx context.Context, projectId, name string, vm *compute.Instance) (*compute.InstanceTemplate, error) {
+
+	if vm == nil {
+		return nil, gceError("CreateInstanceTemplate", fmt.Errorf("vm is nil"))
+	}
+
+	log.Tracef("Create instance template: project[%s], name[%s], machineType[%s]", projectId, name, vm.MachineType)
+
+	template := &compute.InstanceTemplate{
+		Name:        name,
+		Description: vm.Description,
+		Properties:  instanceProperties(vm),
+	}
+	op, err := manager.Service.InstanceTemplates.Insert(projectId, template).
+		RequestId(newRequestId()).Context(ctx).Do()
+	if err != nil {
+		return nil, gceError("CreateInstanceTemplate", err)
+	}
+	if _, err := manager.waitOperation(ctx, op); err != nil {
+		return nil, gceError("CreateInstanceTemplate", err)
+	}
+
+	created, err := manager.Service.InstanceTemplates.Get(projectId, name).Context(ctx).Do()
+
+	return created, gceError("CreateInstanceTemplate", err)
+}
+
+// DeleteInstanceTemplate deletes the instance template, the template used by a managed instance group
+// can not be deleted
+// https://godoc.org/google.golang.org/api/compute/v1#InstanceTemplatesService.Delete
+func (manager *GceManager) DeleteInstanceTemplate(
+	projectId, name string, opts ...OperationOption) (*compute.Operation, error) {
+
+	return manager.DeleteInstanceTemplateContext(context.Background(), projectId, name, opts...)
+}
+
+// DeleteInstanceTemplateContext is DeleteInstanceTemplate with the context of the requests
+func (manager *GceManager) DeleteInstanceTemplateContext(
+	ctx context.Context, projectId, name string, opts ...OperationOption) (*compute.Operation, error) {
+
+	log.Tracef("Delete instance template: project[%s], name[%s]", projectId, name)
+
+	op, err := manager.Service.InstanceTemplates.Delete(projectId, name).Context(ctx).Do()
+	if err != nil {
+		return nil, gceError("DeleteInstanceTemplate", err)
+	}
+	op, err = manager.finishOperation(ctx, op, opts)
+
+	return op, gceError("DeleteInstanceTemplate", err)
+}
+
+// GetManagedInstanceGroup gets the managed instance group
+// https://godoc.org/google.golang.org/api/compute/v1#InstanceGroupManagersService.Get
+func (manager *GceManager) GetManagedInstanceGroup(
+	projectId, zone, name string) (*compute.InstanceGroupManager, error) {
+
+	return manager.GetManagedInstanceGroupContext(context.Background(), projectId, zone, name)
+}
+
+// GetManagedInstanceGroupContext is GetManagedInstanceGroup with the context of the request
+func (manager *GceManager) GetManagedInstanceGroupContext(
+	ctx context.Context, projectId, zone, name string) (*compute.InstanceGroupManager, error) {
+
+	group, err := manager.Service.InstanceGroupManagers.Get(projectId, zone, name).Context(ctx).Do()
+	if err != nil {
+		return nil, gceError("GetManagedInstanceGroup", err)
+	}
+
+	return group, nil
+}
+
+// CreateManagedInstanceGroup creates the managed instance group, and blocks till the operation is DONE.
+// `InstanceTemplate` of the group is the name or the url of an instance template, and the instances
+// of `TargetSize` are created afterwards, see ListManagedInstances for their progress.
+// https://godoc.org/google.golang.org/api/compute/v1#InstanceGroupManagersService.Insert
+func (manager *GceManager) CreateManagedInstanceGroup(
+	projectId, zone string, group *compute.InstanceGroupManager) (*compute.InstanceGroupManager, error) {
+
+	return manager.CreateManagedInstanceGroupContext(context.Background(), projectId, zone, group)
+}
+
+// CreateManagedInstanceGroupContext is CreateManagedInstanceGroup with the context of the requests
+func (manager *GceManager) CreateManagedInstanceGroupContext(
+	ctx context.Context, projectId, zone string, group *compute.InstanceGroupManager) (
+	*compute.InstanceGroupManager, error) {
+
+	if group == nil {
+		return nil, gceError("CreateManagedInstanceGroup", fmt.Errorf("group is nil"))
+	}
+
+	// The group of the caller is kept as given
+	copied := *group
+	group = &copied
+	if !strings.Contains(group.InstanceTemplate, "/") {
+		group.InstanceTemplate = fmt.Sprintf("projects/%s/global/instanceTemplates/%s", projectId, group.InstanceTemplate)
+	}
+	if group.BaseInstanceName == "" {
+		group.BaseInstanceName = group.Name
+	}
+
+	log.Tracef("Create managed instance group: project[%s], zone[%s], name[%s], template[%s], size[%d]",
+		projectId, zone, group.Name, group.InstanceTemplate, group.TargetSize)
+
+	op, err := manager.Service.InstanceGroupManagers.Insert(projectId, zone, group).
+		RequestId(newRequestId()).Context(ctx).Do()
+	if err != nil {
+		return nil, gceError("CreateManagedInstanceGroup", err)
+	}
+	if _, err := manager.waitOperation(ctx, op); err != nil {
+		return nil, gceError("CreateManagedInstanceGroup", err)
+	}
+
+	created, err := manager.Service.InstanceGroupManagers.Get(projectId, zone, group.Name).Context(ctx).Do()
+
+	return created, gceError("CreateManagedInstanceGroup", err)
+}
+
+// DeleteManagedInstanceGroup deletes the managed instance group along with all its instances
+// https://godoc.org/google.golang.org/api/compute/v1#InstanceGroupManagersService.Delete
+func (manager *GceManager) DeleteManagedInstanceGroup(
+	projectId, zone, name string, opts ...OperationOption) (*compute.Operation, error) {
+
+	return manager.DeleteManagedInstanceGroupContext(context.Background(), projectId, zone, name, opts...)
+}
+
+// DeleteManagedInstanceGroupContext is DeleteManagedInstanceGroup with the context of the requests
+func (manager *GceManager) DeleteManagedInstanceGroupContext(
+	ctx context.Context, projectId, zone, name string, opts ...OperationOption) (*compute.Operation, error) {
+
+	log.Tracef("Delete managed instance group: project[%s], zone[%s], name[%s]", projectId, zone, name)
+
+	op, err := manager.Service.InstanceGroupManagers.Delete(projectId, zone, name).Context(ctx).Do()
+	if err != nil {
+		return nil, gceError("DeleteManagedInstanceGroup", err)
+	}
+	op, err = manager.finishOperation(ctx, op, opts)
+
+	return op, gceError("DeleteManagedInstanceGroup", err)
+}
+
+// ResizeManagedInstanceGroup sets the target size of the managed instance group, the instances are
+// created or deleted afterwards
+// https://godoc.org/google.golang.org/api/compute/v1#InstanceGroupManagersService.Resize
+func (manager *GceManager) ResizeManagedInstanceGroup(
+	projectId, zone, name string, size int64, opts ...OperationOption) (*compute.Operation, error) {
+
+	return manager.ResizeManagedInstanceGroupContext(context.Background(), projectId, zone, name, size, opts...)
+}
+
+// ResizeManagedInstanceGroupContext is ResizeManagedInstanceGroup with the context of the requests
+func (manager *GceManager) ResizeManagedInstanceGroupContext(
+	ctx context.Context, projectId, zone, name string, size int64, opts ...OperationOption) (
+	*compute.Operation, error) {
+
+	log.Tracef("Resize managed instance group: project[%s], zone[%s], name[%s], size[%d]",
+		projectId, zone, name, size)
+
+	op, err := manager.Service.InstanceGroupManagers.Resize(projectId, zone, name, size).Context(ctx).Do()
+	if err != nil {
+		return nil, gceError("ResizeManagedInstanceGroup", err)
+	}
+	op, err = manager.finishOperation(ctx, op, opts)
+
+	return op, gceError("ResizeManagedInstanceGroup", err)
+}
+
+// RecreateManagedInstances deletes the instances of the managed instance group and creates them again
+// from the current instance template, the instances are the names or the urls of the instances
+// https://godoc.org/google.golang.org/api/compute/v1#InstanceGroupManagersService.RecreateInstances
+func (manager *GceManager) RecreateManagedInstances(
+	projectId, zone, name string, instances []string, opts ...OperationOption) (*compute.Operation, error) {
+
+	return manager.RecreateManagedInstancesContext(context.Background(), projectId, zone, name, instances, opts...)
+}
+
+// RecreateManagedInstancesContext is RecreateManagedInstances with the context of the requests
+func (manager *GceManager) RecreateManagedInstancesContext(
+	ctx context.Context, projectId, zone, name string, instances []string, opts ...OperationOption) (
+	*compute.Operation, error) {
+
+	log.Tracef("Recreate managed instances: project[%s], zone[%s], name[%s], instances[%s]",
+		projectId, zone, name, instances)
+
+	request := &compute.InstanceGroupManagersRecreateInstancesRequest{
+		Instances: instanceLinks(projectId, zone, instances),
+	}
+	op, err := manager.Service.InstanceGroupManagers.RecreateInstances(projectId, zone, name, request).
+		Context(ctx).Do()
+	if err != nil {
+		return nil, gceError("RecreateManagedInstances", err)
+	}
+	op, err = manager.finishOperation(ctx, op, opts)
+
+	return op, gceError("RecreateManagedInstances", err)
+}
+
+// AbandonManagedInstances removes the instances from the managed instance group without deleting them,
+// and the target size of the group is reduced by their number
+// https://godoc.org/google.golang.org/api/compute/v1#InstanceGroupManagersService.AbandonInstances
+func (manager *GceManager) AbandonManagedInstances(
+	projectId, zone, name string, instances []string, opts ...OperationOption) (*compute.Operation, error) {
+
+	return manager.AbandonManagedInstancesContext(context.Background(), projectId, zone, name, instances, opts...)
+}
+
+// AbandonManagedInstancesContext is AbandonManagedInstances with the context of the requests
+func (manager *GceManager) AbandonManagedInstancesContext(
+	ctx context.Context, projectId, zone, name string, instances []string, opts ...OperationOption) (
+	*compute.Operation, error) {
+
+	log.Tracef("Abandon managed instances: project[%s], zone[%s], name[%s], instances[%s]",
+		projectId, zone, name, instances)
+
+	request := &compute.InstanceGroupManagersAbandonInstancesRequest{
+		Instances: instanceLinks(projectId, zone, instances),
+	}
+	op, err := manager.Service.InstanceGroupManagers.AbandonInstances(projectId, zone, name, request).
+		Context(ctx).Do()
+	if err != nil {
+		return nil, gceError("AbandonManagedInstances", err)
+	}
+	op, err = manager.finishOperation(ctx, op, opts)
+
+	return op, gceError("AbandonManagedInstances", err)
+}
+
+// ListManagedInstances lists the instances of the managed instance group with their status and
+// current action, e.g. CREATING, RECREATING or NONE if the instance is stable
+// https://godoc.org/google.golang.org/api/compute/v1#InstanceGroupManagersService.ListManagedInstances
+func (manager *GceManager) ListManagedInstances(projectId, zone, name string) ([]*compute.ManagedInstance, error) {
+	return manager.ListManagedInstancesContext(context.Background(), projectId, zone, name)
+}
+
+// ListManagedInstancesContext is ListManagedInstances with the context of the request
+func (manager *GceManager) ListManagedInstancesContext(
+	ctx context.Context, projectId, zone, name string) ([]*compute.ManagedInstance, error) {
+
+	response, err := manager.Service.InstanceGroupManagers.ListManagedInstances(projectId, zone, name).
+		Context(ctx).Do()
+	if err != nil {
+		return nil, gceError("ListManagedInstances", err)
+	}
+	if response.ManagedInstances == nil {
+		return []*compute.ManagedInstance{}, nil
+	}
+
+	return response.ManagedInstances, nil
+}
+
+// instanceProperties converts the VM spec into the properties of an instance template
+func instanceProperties(vm *compute.Instance) *compute.InstanceProperties {
+	properties := &compute.InstanceProperties{
+		CanIpForward:    vm.CanIpForward,
+		Description:     vm.Description,
+		Labels:          vm.Labels,
+		MachineType:     nameOfLink(vm.MachineType),
+		MinCpuPlatform:  vm.MinCpuPlatform,
+		Scheduling:      vm.Scheduling,
+		ServiceAccounts: vm.ServiceAccounts,
+	}
+	if vm.Metadata != nil {
+		properties.Metadata = &compute.Metadata{Items: vm.Metadata.Items}
+	}
+	if vm.Tags != nil {
+		properties.Tags = &compute.Tags{Items: vm.Tags.Items}
+	}
+	for _, accelerator := range vm.GuestAccelerators {
+		properties.GuestAccelerators = append(properties.GuestAccelerators, &compute.AcceleratorConfig{
+			AcceleratorCount: accelerator.AcceleratorCount,
+			AcceleratorType:  nameOfLink(accelerator.AcceleratorType),
+		})
+	}
+
+	for _, disk := range vm.Disks {
+		if disk == nil {
+			continue
+		}
+		attached := &compute.AttachedDisk{
+			AutoDelete:        disk.AutoDelete,
+			Boot:              disk.Boot,
+			DeviceName:        disk.DeviceName,
+			DiskEncryptionKey: disk.DiskEncryptionKey,
+			Interface:         disk.Interface,
+			Mode:              disk.Mode,
+			Type:              disk.Type,
+		}
+		if disk.Source != "" {
+			attached.Source = nameOfLink(disk.Source)
+		}
+		if params := disk.InitializeParams; params != nil {
+			initialized := *params
+			if initialized.DiskType != "" {
+				initialized.DiskType = nameOfLink(initialized.DiskType)
+			}
+			attached.InitializeParams = &initialized
+		}
+		properties.Disks = append(properties.Disks, attached)
+	}
+
+	for _, nic := range vm.NetworkInterfaces {
+		if nic == nil {
+			continue
+		}
+		networkInterface := &compute.NetworkInterface{
+			Network:    nic.Network,
+			Subnetwork: nic.Subnetwork,
+		}
+		for _, accessConfig := range nic.AccessConfigs {
+			if accessConfig == nil {
+				continue
+			}
+			networkInterface.AccessConfigs = append(networkInterface.AccessConfigs, &compute.AccessConfig{
+				Name: accessConfig.Name,
+				Type: accessConfig.Type,
+			})
+		}
+		properties.NetworkInterfaces = append(properties.NetworkInterfaces, networkInterface)
+	}
+
+	return properties
+}
+
+// instanceLinks converts the names or the urls of the instances into the urls
+func instanceLinks(projectId, zone string, instances []string) []string {
+	links := []string{}
+	for _, reference := range instanceReferences(projectId, zone, instances) {
+		links = append(links, reference.Instance)
+	}
+
+	return links
+}
@@ -0,0 +1,129 @@
+package gce_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/browny/gogoo/gce"
+	"github.com/browny/gogoo/gerror"
+	"github.com/browny/gogoo/gogootest"
+
+	"github.com/stretchr/testify/assert"
+	compute "google.golang.org/api/compute/v1"
+)
+
+func TestManagedInstanceGroup(t *testing.T) {
+	server, g := gogootest.NewGoGoo()
+	defer server.Close()
+	server.AddImage(&compute.Image{Name: "image-test"})
+
+	vm, err := g.InitVmFromTemplate([]byte(`
+name: template-test
+machineType: zones/{{.Zone}}/machineTypes/n1-standard-1
+tags:
+  items: [http-server]
+disks:
+- boot: true
+  autoDelete: true
+  initializeParams:
+    sourceImage: global/images/image-test
+    diskType: zones/{{.Zone}}/diskTypes/pd-ssd
+networkInterfaces:
+- network: global/networks/default
+  networkIP: 10.240.0.100
+  accessConfigs:
+  - type: ONE_TO_ONE_NAT
+    natIP: 104.155.0.100
+`), fakeZone)
+	assert.Nil(t, err)
+
+	// The template is turned into the zone independent properties
+	template, err := g.CreateInstanceTemplate(gogootest.ProjectId, "template-test", vm)
+	assert.Nil(t, err)
+	assert.Equal(t, "n1-standard-1", template.Properties.MachineType)
+	assert.Equal(t, "pd-ssd", template.Properties.Disks[0].InitializeParams.DiskType)
+	assert.Equal(t, "", template.Properties.NetworkInterfaces[0].NetworkIP)
+	assert.Equal(t, "", template.Properties.NetworkInterfaces[0].AccessConfigs[0].NatIP)
+	assert.Equal(t, []string{"http-server"}, template.Properties.Tags.Items)
+
+	spec := &compute.InstanceGroupManager{
+		Name:             "group-test",
+		BaseInstanceName: "web",
+		InstanceTemplate: "template-test",
+		TargetSize:       2,
+	}
+	_, err = g.CreateManagedInstanceGroup(gogootest.ProjectId, fakeZone, nil)
+	assert.NotNil(t, err)
+	group, err := g.CreateManagedInstanceGroup(gogootest.ProjectId, fakeZone, spec)
+	assert.Nil(t, err)
+	assert.Equal(t, "template-test", spec.InstanceTemplate)
+	assert.Equal(t, int64(2), group.TargetSize)
+	assert.True(t, strings.HasSuffix(group.InstanceTemplate, "/global/instanceTemplates/template-test"))
+
+	managed, err := g.ListManagedInstances(gogootest.ProjectId, fakeZone, "group-test")
+	assert.Nil(t, err)
+	assert.Len(t, managed, 2)
+	for _, instance := range managed {
+		assert.Equal(t, "RUNNING", instance.InstanceStatus)
+		assert.Equal(t, "NONE", instance.CurrentAction)
+		assert.Contains(t, instance.Instance, "/instances/web-")
+	}
+	vm, err = g.GetVm(gogootest.ProjectId, fakeZone, managed[0].Instance[strings.LastIndex(managed[0].Instance, "/")+1:])
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"http-server"}, vm.Tags.Items)
+
+	// The instances are created and deleted by resizing
+	_, err = g.ResizeManagedInstanceGroup(gogootest.ProjectId, fakeZone, "group-test", 3, gce.WaitUntilDone())
+	assert.Nil(t, err)
+	managed, _ = g.ListManagedInstances(gogootest.ProjectId, fakeZone, "group-test")
+	assert.Len(t, managed, 3)
+
+	_, err = g.ResizeManagedInstanceGroup(gogootest.ProjectId, fakeZone, "group-test", 1, gce.WaitUntilDone())
+	assert.Nil(t, err)
+	managed, _ = g.ListManagedInstances(gogootest.ProjectId, fakeZone, "group-test")
+	assert.Len(t, managed, 1)
+	vms, _ := g.ListVms(gogootest.ProjectId, fakeZone)
+	assert.Len(t, vms.Items, 1)
+
+	// The recreated instance is RECREATING till it is RUNNING again
+	server.SetStatusTransitions(true)
+	name := managed[0].Instance[strings.LastIndex(managed[0].Instance, "/")+1:]
+	_, err = g.RecreateManagedInstances(gogootest.ProjectId, fakeZone, "group-test", []string{name}, gce.WaitUntilDone())
+	assert.Nil(t, err)
+	actions := []string{}
+	for i := 0; i < 3; i++ {
+		managed, _ = g.ListManagedInstances(gogootest.ProjectId, fakeZone, "group-test")
+		actions = append(actions, managed[0].CurrentAction)
+	}
+	assert.Equal(t, []string{"RECREATING", "RECREATING", "NONE"}, actions)
+	server.SetStatusTransitions(false)
+
+	_, err = g.RecreateManagedInstances(
+		gogootest.ProjectId, fakeZone, "group-test", []string{"instance-unknown"}, gce.WaitUntilDone())
+	assert.NotNil(t, err)
+
+	// The abandoned instance is kept
+	_, err = g.AbandonManagedInstances(gogootest.ProjectId, fakeZone, "group-test", []string{name}, gce.WaitUntilDone())
+	assert.Nil(t, err)
+	managed, _ = g.ListManagedInstances(gogootest.ProjectId, fakeZone, "group-test")
+	assert.Empty(t, managed)
+	group, _ = g.GetManagedInstanceGroup(gogootest.ProjectId, fakeZone, "group-test")
+	assert.Equal(t, int64(0), group.TargetSize)
+	_, err = g.GetVm(gogootest.ProjectId, fakeZone, name)
+	assert.Nil(t, err)
+
+	// The instances are deleted along with the group
+	_, err = g.ResizeManagedInstanceGroup(gogootest.ProjectId, fakeZone, "group-test", 2, gce.WaitUntilDone())
+	assert.Nil(t, err)
+	_, err = g.DeleteManagedInstanceGroup(gogootest.ProjectId, fakeZone, "group-test", gce.WaitUntilDone())
+	assert.Nil(t, err)
+	_, err = g.GetManagedInstanceGroup(gogootest.ProjectId, fakeZone, "group-test")
+	assert.True(t, gerror.IsNotFound(err))
+	vms, _ = g.ListVms(gogootest.ProjectId, fakeZone)
+	assert.Len(t, vms.Items, 1)
+
+	_, err = g.DeleteInstanceTemplate(gogootest.ProjectId, "template-test", gce.WaitUntilDone())
+	assert.Nil(t, err)
+	_, err = g.GetInstanceTemplate(gogootest.ProjectId, "template-test")
+	assert.True(t, gerror.IsNotFound(err))
+}
@@ -0,0 +1,326 @@
+package gce
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/cihub/seelog"
+	"golang.org/x/net/context"
+	compute "google.golang.org/api/compute/v1"
+)
+
+// Keys of the metadata items used by the guest environment of compute engine
+const (
+	MetadataStartupScript = "startup-script"
+	MetadataSshKeys       = "ssh-keys"
+)
+
+// GetLabels gets the labels of VM, it is empty if the VM has no label
+func (manager *GceManager) GetLabels(projectId, zone, vmName string) (map[string]string, error) {
+	return manager.GetLabelsContext(context.Background(), projectId, zone, vmName)
+}
+
+// GetLabelsContext is GetLabels with the context of the request
+func (manager *GceManager) GetLabelsContext(
+	ctx context.Context, projectId, zone, vmName string) (map[string]string, error) {
+
+	vm, err := manager.GetVmContext(ctx, projectId, zone, vmName)
+	if err != nil {
+		return nil, gceError("GetLabels", err)
+	}
+
+	return copyMap(vm.Labels), nil
+}
+
+// SetLabels replaces all labels of VM, and blocks till the operation is DONE
+// https://godoc.org/google.golang.org/api/compute/v1#InstancesService.SetLabels
+func (manager *GceManager) SetLabels(projectId, zone, vmName string, labels map[string]string) error {
+	return manager.SetLabelsContext(context.Background(), projectId, zone, vmName, labels)
+}
+
+// SetLabelsContext is SetLabels with the context of the requests
+func (manager *GceManager) SetLabelsContext(
+	ctx context.Context, projectId, zone, vmName string, labels map[string]string) error {
+
+	log.Tracef("SetLabels: vm[%s], labels[%v]", vmName, labels)
+
+	change := func(map[string]string) map[string]string {
+		return copyMap(labels)
+	}
+
+	return manager.adjustLabels(ctx, projectId, zone, vmName, "SetLabels", labels, change)
+}
+
+// MergeLabels adds the labels onto VM, the existing labels of the same keys are overwritten.
+// It blocks till the operation is DONE.
+func (manager *GceManager) MergeLabels(projectId, zone, vmName string, labels map[string]string) error {
+	return manager.MergeLabelsContext(context.Background(), projectId, zone, vmName, labels)
+}
+
+// MergeLabelsContext is MergeLabels with the context of the requests
+func (manager *GceManager) MergeLabelsContext(
+	ctx context.Context, projectId, zone, vmName string, labels map[string]string) error {
+
+	log.Tracef("MergeLabels: vm[%s], labels[%v]", vmName, labels)
+
+	change := func(current map[string]string) map[string]string {
+		for key, value := range labels {
+			current[key] = value
+		}
+		return current
+	}
+
+	return manager.adjustLabels(ctx, projectId, zone, vmName, "MergeLabels", labels, change)
+}
+
+// RemoveLabels removes the labels of the keys from VM, and blocks till the operation is DONE
+func (manager *GceManager) RemoveLabels(projectId, zone, vmName string, keys []string) error {
+	return manager.RemoveLabelsContext(context.Background(), projectId, zone, vmName, keys)
+}
+
+// RemoveLabelsContext is RemoveLabels with the context of the requests
+func (manager *GceManager) RemoveLabelsContext(ctx context.Context, projectId, zone, vmName string, keys []string) error {
+	log.Tracef("RemoveLabels: vm[%s], keys[%s]", vmName, keys)
+
+	change := func(current map[string]string) map[string]string {
+		for _, key := range keys {
+			delete(current, key)
+		}
+		return current
+	}
+
+	return manager.adjustLabels(ctx, projectId, zone, vmName, "RemoveLabels", nil, change)
+}
+
+// adjustLabels validates the labels, and updates the labels of VM by change with the label fingerprint
+func (manager *GceManager) adjustLabels(ctx context.Context, projectId, zone, vmName, operation string,
+	labels map[string]string, change func(map[string]string) map[string]string) error {
+
+	for key, value := range labels {
+		if !labelKeyPattern.MatchString(key) || !labelValuePattern.MatchString(value) {
+			return gceError(operation, fmt.Errorf("invalid label %s=%s", key, value))
+		}
+	}
+
+	op, err := manager.updateVm(ctx, projectId, zone, vmName, func(vm *compute.Instance) (*compute.Operation, error) {
+		request := &compute.InstancesSetLabelsRequest{
+			Labels:           change(copyMap(vm.Labels)),
+			LabelFingerprint: vm.LabelFingerprint,
+		}
+		return manager.Service.Instances.SetLabels(projectId, zone, vmName, request).Context(ctx).Do()
+	})
+	if err != nil {
+		return gceError(operation, err)
+	}
+	_, err = manager.waitOperation(ctx, op)
+
+	return gceError(operation, err)
+}
+
+// GetMetadata gets the metadata items of VM by their keys, it is empty if the VM has no metadata.
+// The value of an item without value is empty.
+func (manager *GceManager) GetMetadata(projectId, zone, vmName string) (map[string]string, error) {
+	return manager.GetMetadataContext(context.Background(), projectId, zone, vmName)
+}
+
+// GetMetadataContext is GetMetadata with the context of the request
+func (manager *GceManager) GetMetadataContext(
+	ctx context.Context, projectId, zone, vmName string) (map[string]string, error) {
+
+	vm, err := manager.GetVmContext(ctx, projectId, zone, vmName)
+	if err != nil {
+		return nil, gceError("GetMetadata", err)
+	}
+
+	return metadataItems(vm.Metadata), nil
+}
+
+// SetMetadata replaces all metadata items of VM, and blocks till the operation is DONE
+// https://godoc.org/google.golang.org/api/compute/v1#InstancesService.SetMetadata
+func (manager *GceManager) SetMetadata(projectId, zone, vmName string, items map[string]string) error {
+	return manager.SetMetadataContext(context.Background(), projectId, zone, vmName, items)
+}
+
+// SetMetadataContext is SetMetadata with the context of the requests
+func (manager *GceManager) SetMetadataContext(
+	ctx context.Context, projectId, zone, vmName string, items map[string]string) error {
+
+	log.Tracef("SetMetadata: vm[%s], keys[%s]", vmName, keysOf(items))
+
+	change := func(map[string]string) map[string]string {
+		return copyMap(items)
+	}
+
+	return manager.adjustMetadata(ctx, projectId, zone, vmName, "SetMetadata", change)
+}
+
+// MergeMetadata adds the metadata items onto VM, the existing items of the same keys are overwritten.
+// It blocks till the operation is DONE.
+func (manager *GceManager) MergeMetadata(projectId, zone, vmName string, items map[string]string) error {
+	return manager.MergeMetadataContext(context.Background(), projectId, zone, vmName, items)
+}
+
+// MergeMetadataContext is MergeMetadata with the context of the requests
+func (manager *GceManager) MergeMetadataContext(
+	ctx context.Context, projectId, zone, vmName string, items map[string]string) error {
+
+	log.Tracef("MergeMetadata: vm[%s], keys[%s]", vmName, keysOf(items))
+
+	change := func(current map[string]string) map[string]string {
+		for key, value := range items {
+			current[key] = value
+		}
+		return current
+	}
+
+	return manager.adjustMetadata(ctx, projectId, zone, vmName, "MergeMetadata", change)
+}
+
+// RemoveMetadata removes the metadata items of the keys from VM, and blocks till the operation is DONE
+func (manager *GceManager) RemoveMetadata(projectId, zone, vmName string, keys []string) error {
+	return manager.RemoveMetadataContext(context.Background(), projectId, zone, vmName, keys)
+}
+
+// RemoveMetadataContext is RemoveMetadata with the context of the requests
+func (manager *GceManager) RemoveMetadataContext(ctx context.Context, projectId, zone, vmName string, keys []string) error {
+	log.Tracef("RemoveMetadata: vm[%s], keys[%s]", vmName, keys)
+
+	change := func(current map[string]string) map[string]string {
+		for _, key := range keys {
+			delete(current, key)
+		}
+		return current
+	}
+
+	return manager.adjustMetadata(ctx, projectId, zone, vmName, "RemoveMetadata", change)
+}
+
+// SetStartupScript sets the `startup-script` of VM, which is run on the next boot.
+// It blocks till the operation is DONE.
+func (manager *GceManager) SetStartupScript(projectId, zone, vmName, script string) error {
+	return manager.SetStartupScriptContext(context.Background(), projectId, zone, vmName, script)
+}
+
+// SetStartupScriptContext is SetStartupScript with the context of the requests
+func (manager *GceManager) SetStartupScriptContext(ctx context.Context, projectId, zone, vmName, script string) error {
+	log.Tracef("SetStartupScript: vm[%s]", vmName)
+
+	change := func(current map[string]string) map[string]string {
+		current[MetadataStartupScript] = script
+		return current
+	}
+
+	return manager.adjustMetadata(ctx, projectId, zone, vmName, "SetStartupScript", change)
+}
+
+// AddSshKeys adds the public keys of the users onto `ssh-keys` of VM, the keys already added are
+// not duplicated. A key is in the form "{user}:ssh-rsa {key} {comment}".
+// It blocks till the operation is DONE.
+func (manager *GceManager) AddSshKeys(projectId, zone, vmName string, keys []string) error {
+	return manager.AddSshKeysContext(context.Background(), projectId, zone, vmName, keys)
+}
+
+// AddSshKeysContext is AddSshKeys with the context of the requests
+func (manager *GceManager) AddSshKeysContext(ctx context.Context, projectId, zone, vmName string, keys []string) error {
+	log.Tracef("AddSshKeys: vm[%s], count[%d]", vmName, len(keys))
+
+	for _, key := range keys {
+		if !strings.Contains(key, ":") || strings.Contains(key, "\n") {
+			return gceError("AddSshKeys", fmt.Errorf("invalid ssh key[%s], {user}:{key} is expected", key))
+		}
+	}
+
+	change := func(current map[string]string) map[string]string {
+		lines := []string{}
+		if current[MetadataSshKeys] != "" {
+			lines = strings.Split(current[MetadataSshKeys], "\n")
+		}
+		current[MetadataSshKeys] = strings.Join(TagChange{Add: keys}.apply(lines), "\n")
+		return current
+	}
+
+	return manager.adjustMetadata(ctx, projectId, zone, vmName, "AddSshKeys", change)
+}
+
+// adjustMetadata updates the metadata items of VM by change with the metadata fingerprint
+func (manager *GceManager) adjustMetadata(ctx context.Context, projectId, zone, vmName, operation string,
+	change func(map[string]string) map[string]string) error {
+
+	op, err := manager.updateVm(ctx, projectId, zone, vmName, func(vm *compute.Instance) (*compute.Operation, error) {
+		metadata := &compute.Metadata{}
+		if vm.Metadata != nil {
+			metadata.Fingerprint = vm.Metadata.Fingerprint
+		}
+		items := change(metadataItems(vm.Metadata))
+		for _, key := range keysOf(items) {
+			value := items[key]
+			item := &compute.MetadataItems{Key: key, Value: &value}
+			// The item without value is kept so unless the change sets it
+			if value == "" && withoutValue(vm.Metadata, key) {
+				item.Value = nil
+			}
+			metadata.Items = append(metadata.Items, item)
+		}
+		return manager.Service.Instances.SetMetadata(projectId, zone, vmName, metadata).Context(ctx).Do()
+	})
+	if err != nil {
+		return gceError(operation, err)
+	}
+	_, err = manager.waitOperation(ctx, op)
+
+	return gceError(operation, err)
+}
+
+// metadataItems converts the metadata to the map of items, the value of an item without value is empty
+func metadataItems(metadata *compute.Metadata) map[string]string {
+	items := map[string]string{}
+	if metadata == nil {
+		return items
+	}
+	for _, item := range metadata.Items {
+		if item == nil {
+			continue
+		}
+		items[item.Key] = ""
+		if item.Value != nil {
+			items[item.Key] = *item.Value
+		}
+	}
+
+	return items
+}
+
+// withoutValue tells whether the metadata has the item of key without value
+func withoutValue(metadata *compute.Metadata, key string) bool {
+	if metadata == nil {
+		return false
+	}
+	for _, item := range metadata.Items {
+		if item != nil && item.Key == key {
+			return item.Value == nil
+		}
+	}
+
+	return false
+}
+
+func copyMap(labels map[string]string) map[string]string {
+	result := map[string]string{}
+	for key, value := range labels {
+		result[key] = value
+	}
+
+	return result
+}
+
+// keysOf returns the sorted keys of the map
+func keysOf(items map[string]string) []string {
+	keys := []string{}
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,96 @@
+package gce_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/browny/gogoo/gce"
+	"github.com/browny/gogoo/gogootest"
+
+	"github.com/stretchr/testify/assert"
+	compute "google.golang.org/api/compute/v1"
+)
+
+func TestLabelsAndMetadata(t *testing.T) {
+	server, g := gogootest.NewGoGoo()
+	defer server.Close()
+
+	vm := fakeVm("instance-test")
+	vm.Labels = map[string]string{"owner": "alice"}
+	vm.Metadata = &compute.Metadata{Items: []*compute.MetadataItems{{Key: "enable-oslogin"}}}
+	server.AddInstance(fakeZone, vm)
+
+	labels, err := g.GetLabels(gogootest.ProjectId, fakeZone, "instance-test")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"owner": "alice"}, labels)
+
+	assert.Nil(t, g.MergeLabels(gogootest.ProjectId, fakeZone, "instance-test", map[string]string{"env": "prod"}))
+	labels, _ = g.GetLabels(gogootest.ProjectId, fakeZone, "instance-test")
+	assert.Equal(t, map[string]string{"owner": "alice", "env": "prod"}, labels)
+
+	assert.Nil(t, g.RemoveLabels(gogootest.ProjectId, fakeZone, "instance-test", []string{"owner"}))
+	labels, _ = g.GetLabels(gogootest.ProjectId, fakeZone, "instance-test")
+	assert.Equal(t, map[string]string{"env": "prod"}, labels)
+
+	assert.Nil(t, g.SetLabels(gogootest.ProjectId, fakeZone, "instance-test", map[string]string{"team": "infra"}))
+	labels, _ = g.GetLabels(gogootest.ProjectId, fakeZone, "instance-test")
+	assert.Equal(t, map[string]string{"team": "infra"}, labels)
+
+	assert.NotNil(t, g.MergeLabels(gogootest.ProjectId, fakeZone, "instance-test", map[string]string{"Bad": "x"}))
+
+	// Metadata
+	assert.Nil(t, g.SetStartupScript(gogootest.ProjectId, fakeZone, "instance-test", "#!/bin/bash\necho hello"))
+	assert.Nil(t, g.AddSshKeys(gogootest.ProjectId, fakeZone, "instance-test", []string{"alice:ssh-rsa AAAA alice"}))
+	assert.Nil(t, g.AddSshKeys(gogootest.ProjectId, fakeZone, "instance-test",
+		[]string{"alice:ssh-rsa AAAA alice", "bob:ssh-rsa BBBB bob"}))
+	assert.NotNil(t, g.AddSshKeys(gogootest.ProjectId, fakeZone, "instance-test", []string{"ssh-rsa CCCC"}))
+	assert.Nil(t, g.MergeMetadata(gogootest.ProjectId, fakeZone, "instance-test", map[string]string{"owner": "alice"}))
+
+	items, err := g.GetMetadata(gogootest.ProjectId, fakeZone, "instance-test")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		gce.MetadataStartupScript: "#!/bin/bash\necho hello",
+		gce.MetadataSshKeys:       "alice:ssh-rsa AAAA alice\nbob:ssh-rsa BBBB bob",
+		"owner":                   "alice",
+		"enable-oslogin":          "",
+	}, items)
+
+	assert.Nil(t, g.RemoveMetadata(gogootest.ProjectId, fakeZone, "instance-test", []string{gce.MetadataSshKeys}))
+	items, _ = g.GetMetadata(gogootest.ProjectId, fakeZone, "instance-test")
+	assert.Len(t, items, 3)
+
+	// The item without value is kept without value
+	vm, _ = g.GetVm(gogootest.ProjectId, fakeZone, "instance-test")
+	withoutValue := []string{}
+	for _, item := range vm.Metadata.Items {
+		if item.Value == nil {
+			withoutValue = append(withoutValue, item.Key)
+		}
+	}
+	assert.Equal(t, []string{"enable-oslogin"}, withoutValue)
+	assert.Nil(t, g.RemoveMetadata(gogootest.ProjectId, fakeZone, "instance-test", []string{"enable-oslogin"}))
+	items, _ = g.GetMetadata(gogootest.ProjectId, fakeZone, "instance-test")
+	assert.Len(t, items, 2)
+
+	assert.Nil(t, g.SetMetadata(gogootest.ProjectId, fakeZone, "instance-test", map[string]string{}))
+	items, _ = g.GetMetadata(gogootest.ProjectId, fakeZone, "instance-test")
+	assert.Empty(t, items)
+
+	// The concurrent updates retry on the mismatch of fingerprint, none of them is lost
+	var wg sync.WaitGroup
+	for i := 0; i < gce.FingerprintMaxAttempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("key-%d", i)
+			assert.Nil(t, g.MergeMetadata(gogootest.ProjectId, fakeZone, "instance-test", map[string]string{key: "v"}))
+			assert.Nil(t, g.MergeLabels(gogootest.ProjectId, fakeZone, "instance-test", map[string]string{key: "v"}))
+		}(i)
+	}
+	wg.Wait()
+	items, _ = g.GetMetadata(gogootest.ProjectId, fakeZone, "instance-test")
+	assert.Len(t, items, gce.FingerprintMaxAttempts)
+	labels, _ = g.GetLabels(gogootest.ProjectId, fakeZone, "instance-test")
+	assert.Len(t, labels, 1+gce.FingerprintMaxAttempts)
+}
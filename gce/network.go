@@ -0,0 +1,255 @@
+package gce
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/browny/gogoo/gerror"
+
+	log "github.com/cihub/seelog"
+	"golang.org/x/net/context"
+	compute "google.golang.org/api/compute/v1"
+)
+
+// AccessConfigName is the name of the access config added by default, as the console does
+const AccessConfigName = "External NAT"
+
+// InterfaceAddresses are the addresses of a network interface of VM
+type InterfaceAddresses struct {
+	// Name is the name of the network interface, e.g. "nic0"
+	Name      string
+	Network   string
+	NetworkIP string
+	// NatIPs are the external IPs of the access configs, which are empty if the VM is stopped
+	NatIPs []string
+}
+
+// GetInterfaceAddresses gets the addresses of all network interfaces of VM, in the order of the interfaces
+func (manager *GceManager) GetInterfaceAddresses(vm *compute.Instance) ([]*InterfaceAddresses, error) {
+	if vm == nil {
+		return nil, gceError("GetInterfaceAddresses", fmt.Errorf("vm is nil"))
+	}
+
+	result := []*InterfaceAddresses{}
+	for _, nic := range vm.NetworkInterfaces {
+		if nic == nil {
+			continue
+		}
+		addresses := &InterfaceAddresses{
+			Name:      nic.Name,
+			Network:   nic.Network,
+			NetworkIP: nic.NetworkIP,
+			NatIPs:    []string{},
+		}
+		for _, accessConfig := range nic.AccessConfigs {
+			if accessConfig != nil && accessConfig.NatIP != "" {
+				addresses.NatIPs = append(addresses.NatIPs, accessConfig.NatIP)
+			}
+		}
+		result = append(result, addresses)
+	}
+
+	return result, nil
+}
+
+// GetNatIPs gets the external IPs of all network interfaces of VM, which is empty if the VM has no external IP
+func (manager *GceManager) GetNatIPs(vm *compute.Instance) ([]string, error) {
+	addresses, err := manager.GetInterfaceAddresses(vm)
+	if err != nil {
+		return nil, gceError("GetNatIPs", err)
+	}
+
+	natIPs := []string{}
+	for _, nic := range addresses {
+		natIPs = append(natIPs, nic.NatIPs...)
+	}
+
+	return natIPs, nil
+}
+
+// GetAddress gets the static address of the region.
+// https://godoc.org/google.golang.org/api/compute/v1#AddressesService.Get
+func (manager *GceManager) GetAddress(projectId, region, name string) (*compute.Address, error) {
+	return manager.GetAddressContext(context.Background(), projectId, region, name)
+}
+
+// GetAddressContext is GetAddress with the context of the request
+func (manager *GceManager) GetAddressContext(
+	ctx context.Context, projectId, region, name string) (*compute.Address, error) {
+
+	log.Tracef("Get address: project[%s], region[%s], name[%s]", projectId, region, name)
+
+	address, err := manager.Service.Addresses.Get(projectId, region, name).Context(ctx).Do()
+	if err != nil {
+		return nil, gceError("GetAddress", err)
+	}
+
+	return address, nil
+}
+
+// ReserveAddress reserves the static external address in the region, and blocks till the operation
+// is DONE. A new IP is allocated if `address.Address` is empty, or the given ephemeral IP in use
+// is promoted to static.
+// https://godoc.org/google.golang.org/api/compute/v1#AddressesService.Insert
+func (manager *GceManager) ReserveAddress(
+	projectId, region string, address *compute.Address) (*compute.Address, error) {
+
+	return manager.ReserveAddressContext(context.Background(), projectId, region, address)
+}
+
+// ReserveAddressContext is ReserveAddress with the context of the requests
+func (manager *GceManager) ReserveAddressContext(
+	ctx context.Context, projectId, region string, address *compute.Address) (*compute.Address, error) {
+
+	if address == nil {
+		return nil, gceError("ReserveAddress", fmt.Errorf("address is nil"))
+	}
+
+	log.Tracef("Reserve address: project[%s], region[%s], name[%s], address[%s]",
+		projectId, region, address.Name, address.Address)
+
+	op, err := manager.Service.Addresses.Insert(projectId, region, address).
+		RequestId(newRequestId()).Context(ctx).Do()
+	if err != nil {
+		return nil, gceError("ReserveAddress", err)
+	}
+	if _, err := manager.waitOperation(ctx, op); err != nil {
+		return nil, gceError("ReserveAddress", err)
+	}
+
+	reserved, err := manager.Service.Addresses.Get(projectId, region, address.Name).Context(ctx).Do()
+
+	return reserved, gceError("ReserveAddress", err)
+}
+
+// ReleaseAddress releases the static address, and blocks till the operation is DONE.
+// The address in use by a VM can not be released.
+// https://godoc.org/google.golang.org/api/compute/v1#AddressesService.Delete
+func (manager *GceManager) ReleaseAddress(projectId, region, name string) error {
+	return manager.ReleaseAddressContext(context.Background(), projectId, region, name)
+}
+
+// ReleaseAddressContext is ReleaseAddress with the context of the requests
+func (manager *GceManager) ReleaseAddressContext(ctx context.Context, projectId, region, name string) error {
+	log.Tracef("Release address: project[%s], region[%s], name[%s]", projectId, region, name)
+
+	op, err := manager.Service.Addresses.Delete(projectId, region, name).Context(ctx).Do()
+	if err != nil {
+		return gceError("ReleaseAddress", err)
+	}
+	_, err = manager.waitOperation(ctx, op)
+
+	return gceError("ReleaseAddress", err)
+}
+
+// AddAccessConfig adds the access config to the network interface of VM, e.g. "nic0", and blocks till
+// the operation is DONE. The access config gets an ephemeral IP if `NatIP` is empty, and its name is
+// AccessConfigName by default. A network interface has at most one access config.
+// https://godoc.org/google.golang.org/api/compute/v1#InstancesService.AddAccessConfig
+func (manager *GceManager) AddAccessConfig(
+	projectId, zone, vmName, networkInterface string, accessConfig *compute.AccessConfig) error {
+
+	return manager.AddAccessConfigContext(context.Background(), projectId, zone, vmName, networkInterface, accessConfig)
+}
+
+// AddAccessConfigContext is AddAccessConfig with the context of the requests
+func (manager *GceManager) AddAccessConfigContext(
+	ctx context.Context, projectId, zone, vmName, networkInterface string, accessConfig *compute.AccessConfig) error {
+
+	if accessConfig == nil {
+		return gceError("AddAccessConfig", fmt.Errorf("access config is nil"))
+	}
+
+	log.Tracef("Add access config: project[%s], zone[%s], vmName[%s], nic[%s], natIP[%s]",
+		projectId, zone, vmName, networkInterface, accessConfig.NatIP)
+
+	// The access config of the caller is kept as given
+	config := *accessConfig
+	if config.Name == "" {
+		config.Name = AccessConfigName
+	}
+	if config.Type == "" {
+		config.Type = "ONE_TO_ONE_NAT"
+	}
+
+	op, err := manager.Service.Instances.AddAccessConfig(projectId, zone, vmName, networkInterface, &config).
+		Context(ctx).Do()
+	if err != nil {
+		return gceError("AddAccessConfig", err)
+	}
+	_, err = manager.waitOperation(ctx, op)
+
+	return gceError("AddAccessConfig", err)
+}
+
+// DeleteAccessConfig deletes the access config from the network interface of VM, and blocks till
+// the operation is DONE. The VM loses its external IP, and the ephemeral IP is released.
+// https://godoc.org/google.golang.org/api/compute/v1#InstancesService.DeleteAccessConfig
+func (manager *GceManager) DeleteAccessConfig(projectId, zone, vmName, networkInterface, accessConfigName string) error {
+	return manager.DeleteAccessConfigContext(
+		context.Background(), projectId, zone, vmName, networkInterface, accessConfigName)
+}
+
+// DeleteAccessConfigContext is DeleteAccessConfig with the context of the requests
+func (manager *GceManager) DeleteAccessConfigContext(
+	ctx context.Context, projectId, zone, vmName, networkInterface, accessConfigName string) error {
+
+	log.Tracef("Delete access config: project[%s], zone[%s], vmName[%s], nic[%s], accessConfig[%s]",
+		projectId, zone, vmName, networkInterface, accessConfigName)
+
+	op, err := manager.Service.Instances.DeleteAccessConfig(projectId, zone, vmName, accessConfigName, networkInterface).
+		Context(ctx).Do()
+	if err != nil {
+		return gceError("DeleteAccessConfig", err)
+	}
+	_, err = manager.waitOperation(ctx, op)
+
+	return gceError("DeleteAccessConfig", err)
+}
+
+// PromoteToStaticIP reserves the ephemeral external IP of the running VM as the static address,
+// so the VM keeps its IP after being stopped or deleted. The first external IP of VM is promoted.
+// The error has ReasonNotFound if the VM has no external IP.
+func (manager *GceManager) PromoteToStaticIP(projectId, zone, vmName, addressName string) (*compute.Address, error) {
+	return manager.PromoteToStaticIPContext(context.Background(), projectId, zone, vmName, addressName)
+}
+
+// PromoteToStaticIPContext is PromoteToStaticIP with the context of the requests
+func (manager *GceManager) PromoteToStaticIPContext(
+	ctx context.Context, projectId, zone, vmName, addressName string) (*compute.Address, error) {
+
+	log.Tracef("Promote to static IP: project[%s], zone[%s], vmName[%s], address[%s]",
+		projectId, zone, vmName, addressName)
+
+	vm, err := manager.Service.Instances.Get(projectId, zone, vmName).Context(ctx).Do()
+	if err != nil {
+		return nil, gceError("PromoteToStaticIP", err)
+	}
+	natIPs, err := manager.GetNatIPs(vm)
+	if err != nil {
+		return nil, gceError("PromoteToStaticIP", err)
+	}
+	if len(natIPs) == 0 {
+		return nil, &gerror.Error{
+			Service:   "gce",
+			Operation: "PromoteToStaticIP",
+			Reason:    gerror.ReasonNotFound,
+			Err:       fmt.Errorf("No external IP: vm[%s]", vmName),
+		}
+	}
+
+	address := &compute.Address{Name: addressName, Address: natIPs[0]}
+	reserved, err := manager.ReserveAddressContext(ctx, projectId, regionOfZone(zone), address)
+
+	return reserved, gceError("PromoteToStaticIP", err)
+}
+
+// regionOfZone gets the region of the zone, e.g. "asia-east1" of "asia-east1-b"
+func regionOfZone(zone string) string {
+	zone = nameOfLink(zone)
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		return zone[:i]
+	}
+
+	return zone
+}
@@ -0,0 +1,93 @@
+package gce_test
+
+import (
+	"testing"
+
+	"github.com/browny/gogoo/gerror"
+	"github.com/browny/gogoo/gogootest"
+
+	"github.com/stretchr/testify/assert"
+	compute "google.golang.org/api/compute/v1"
+)
+
+func TestNetwork(t *testing.T) {
+	server, g := gogootest.NewGoGoo()
+	defer server.Close()
+
+	region := "asia-east1"
+	vm := fakeVm("instance-test")
+	vm.NetworkInterfaces = append(vm.NetworkInterfaces, &compute.NetworkInterface{Network: "global/networks/other"})
+	server.AddInstance(fakeZone, vm)
+	server.AddInstance(fakeZone, &compute.Instance{Name: "instance-internal",
+		NetworkInterfaces: []*compute.NetworkInterface{{Network: "global/networks/default"}}})
+
+	// The accessors do not panic on the VMs without external IP
+	_, err := g.GetNatIP(nil)
+	assert.NotNil(t, err)
+	internal, _ := g.GetVm(gogootest.ProjectId, fakeZone, "instance-internal")
+	_, err = g.GetNatIP(internal)
+	assert.True(t, gerror.IsNotFound(err))
+	_, err = g.GetNetworkIP(&compute.Instance{Name: "instance-no-nic"})
+	assert.True(t, gerror.IsNotFound(err))
+
+	vm, _ = g.GetVm(gogootest.ProjectId, fakeZone, "instance-test")
+	addresses, err := g.GetInterfaceAddresses(vm)
+	assert.Nil(t, err)
+	assert.Len(t, addresses, 2)
+	assert.Equal(t, "nic0", addresses[0].Name)
+	assert.Len(t, addresses[0].NatIPs, 1)
+	assert.Equal(t, "nic1", addresses[1].Name)
+	assert.NotEmpty(t, addresses[1].NetworkIP)
+	assert.Empty(t, addresses[1].NatIPs)
+	ephemeral := addresses[0].NatIPs[0]
+
+	// Promote the ephemeral IP, which can not be released while in use
+	address, err := g.PromoteToStaticIP(gogootest.ProjectId, fakeZone, "instance-test", "address-test")
+	assert.Nil(t, err)
+	assert.Equal(t, ephemeral, address.Address)
+	assert.Equal(t, "IN_USE", address.Status)
+	assert.Equal(t, []string{vm.SelfLink}, address.Users)
+	assert.NotNil(t, g.ReleaseAddress(gogootest.ProjectId, region, "address-test"))
+
+	_, err = g.PromoteToStaticIP(gogootest.ProjectId, fakeZone, "instance-internal", "address-x")
+	assert.True(t, gerror.IsNotFound(err))
+
+	// Move the static IP to another VM
+	assert.Nil(t, g.DeleteAccessConfig(gogootest.ProjectId, fakeZone, "instance-test", "nic0", "External NAT"))
+	address, _ = g.GetAddress(gogootest.ProjectId, region, "address-test")
+	assert.Equal(t, "RESERVED", address.Status)
+	vm, _ = g.GetVm(gogootest.ProjectId, fakeZone, "instance-test")
+	natIPs, err := g.GetNatIPs(vm)
+	assert.Nil(t, err)
+	assert.Empty(t, natIPs)
+
+	assert.Nil(t, g.AddAccessConfig(gogootest.ProjectId, fakeZone, "instance-internal", "nic0",
+		&compute.AccessConfig{NatIP: ephemeral}))
+	internal, _ = g.GetVm(gogootest.ProjectId, fakeZone, "instance-internal")
+	natIP, err := g.GetNatIP(internal)
+	assert.Nil(t, err)
+	assert.Equal(t, ephemeral, natIP)
+	assert.Equal(t, "External NAT", internal.NetworkInterfaces[0].AccessConfigs[0].Name)
+	err = g.AddAccessConfig(gogootest.ProjectId, fakeZone, "instance-internal", "nic0", &compute.AccessConfig{})
+	assert.NotNil(t, err)
+	err = g.AddAccessConfig(gogootest.ProjectId, fakeZone, "instance-internal", "nic0", nil)
+	assert.NotNil(t, err)
+
+	// A new ephemeral IP, and a new static address
+	accessConfig := &compute.AccessConfig{}
+	assert.Nil(t, g.AddAccessConfig(gogootest.ProjectId, fakeZone, "instance-test", "nic0", accessConfig))
+	assert.Equal(t, compute.AccessConfig{}, *accessConfig)
+	vm, _ = g.GetVm(gogootest.ProjectId, fakeZone, "instance-test")
+	natIP, _ = g.GetNatIP(vm)
+	assert.NotEqual(t, ephemeral, natIP)
+
+	_, err = g.ReserveAddress(gogootest.ProjectId, region, nil)
+	assert.NotNil(t, err)
+	address, err = g.ReserveAddress(gogootest.ProjectId, region, &compute.Address{Name: "address-new"})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, address.Address)
+	assert.Equal(t, "RESERVED", address.Status)
+	assert.Nil(t, g.ReleaseAddress(gogootest.ProjectId, region, "address-new"))
+	_, err = g.GetAddress(gogootest.ProjectId, region, "address-new")
+	assert.True(t, gerror.IsNotFound(err))
+}
@@ -0,0 +1,200 @@
+package gce
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/cihub/seelog"
+	"golang.org/x/net/context"
+	compute "google.golang.org/api/compute/v1"
+)
+
+const (
+	OperationPollInterval    = 1 * time.Second
+	OperationMaxPollInterval = 10 * time.Second
+	StatusPollInterval       = 10 * time.Second
+)
+
+// OperationError is the error reported by a finished operation of compute engine
+type OperationError struct {
+	Operation *compute.Operation
+	Errors    []*compute.OperationErrorErrors
+}
+
+func (e *OperationError) Error() string {
+	messages := []string{}
+	for _, err := range e.Errors {
+		messages = append(messages, fmt.Sprintf("%s: %s", err.Code, err.Message))
+	}
+
+	return fmt.Sprintf("%s[%s] of %s: %s",
+		e.Operation.OperationType, e.Operation.Name, e.Operation.TargetLink, strings.Join(messages, "; "))
+}
+
+// OperationOption configures how the mutating methods of GceManager treat their operations
+type OperationOption func(*operationOptions)
+
+type operationOptions struct {
+	wait            bool
+	deleted         bool
+	deletionTimeout time.Duration
+	attachedDisks   bool
+}
+
+// WaitUntilDone makes the mutating method block till its operation is DONE.
+// The returned operation is the finished one, and its error wraps *OperationError.
+func WaitUntilDone() OperationOption {
+	return func(options *operationOptions) {
+		options.wait = true
+	}
+}
+
+// WaitUntilDeleted makes DeleteVm and DeleteDisk block till the resource is not found,
+// it fails if the resource still exists after timeout, `Timeouts.Deletion` of the manager is used if timeout is zero.
+// It implies WaitUntilDone.
+func WaitUntilDeleted(timeout time.Duration) OperationOption {
+	return func(options *operationOptions) {
+		options.wait = true
+		options.deleted = true
+		options.deletionTimeout = timeout
+	}
+}
+
+// WithAttachedDisks makes DeleteVm delete the non-boot disks attached to the VM once the VM is deleted.
+// The boot disk follows its `autoDelete`. It implies WaitUntilDeleted for the VM and the disks,
+// with the timeout given by WaitUntilDeleted if any.
+func WithAttachedDisks() OperationOption {
+	return func(options *operationOptions) {
+		options.wait = true
+		options.deleted = true
+		options.attachedDisks = true
+	}
+}
+
+func (manager *GceManager) newOperationOptions(opts []OperationOption) operationOptions {
+	options := operationOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	options.deletionTimeout = orDefault(options.deletionTimeout, orDefault(manager.Timeouts.Deletion, DeletionTimeout))
+
+	return options
+}
+
+// WaitOperation blocks till the operation is DONE or ctx is done.
+// The operation is polled from zone, region or global operations according to its scope,
+// the interval starts from `OperationPollInterval` and is doubled till `OperationMaxPollInterval`.
+// The error of the finished operation wraps *OperationError, and is classified by its code,
+// e.g. gerror.IsQuotaExceeded(err) for "QUOTA_EXCEEDED".
+// https://godoc.org/google.golang.org/api/compute/v1#ZoneOperationsService.Get
+func (manager *GceManager) WaitOperation(ctx context.Context, op *compute.Operation) error {
+	_, err := manager.waitOperation(ctx, op)
+	return gceError("WaitOperation", err)
+}
+
+// waitOperation waits the operation and returns the finished one, the error is not wrapped
+func (manager *GceManager) waitOperation(ctx context.Context, op *compute.Operation) (*compute.Operation, error) {
+	if op == nil {
+		return nil, fmt.Errorf("operation is nil")
+	}
+	log.Tracef("Wait operation: name[%s], type[%s], target[%s]", op.Name, op.OperationType, op.TargetLink)
+
+	interval := manager.pollInterval()
+	for op.Status != "DONE" {
+		select {
+		case <-ctx.Done():
+			return op, fmt.Errorf("wait operation[%s]: %w", op.Name, ctx.Err())
+		case <-time.After(interval):
+		}
+
+		if interval *= 2; interval > OperationMaxPollInterval {
+			interval = OperationMaxPollInterval
+		}
+
+		polled, err := manager.getOperation(ctx, op)
+		if err != nil {
+			return op, err
+		}
+		op = polled
+	}
+
+	if op.Error != nil && len(op.Error.Errors) > 0 {
+		log.Warnf("Operation fails: name[%s], target[%s]", op.Name, op.TargetLink)
+		return op, &OperationError{Operation: op, Errors: op.Error.Errors}
+	}
+	log.Tracef("Operation done: name[%s]", op.Name)
+
+	return op, nil
+}
+
+// newRequestId generates the random UUID identifying an insert, compute engine ignores the retried
+// insert of the same id which is already applied
+func newRequestId() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// pollInterval is the first interval to poll an operation
+func (manager *GceManager) pollInterval() time.Duration {
+	return orDefault(manager.OperationPollInterval, OperationPollInterval)
+}
+
+// getOperation gets the latest status of the operation
+func (manager *GceManager) getOperation(ctx context.Context, op *compute.Operation) (*compute.Operation, error) {
+	projectId := projectOfLink(op.SelfLink)
+	if projectId == "" {
+		return nil, fmt.Errorf("unknown project of operation[%s]", op.Name)
+	}
+
+	var result *compute.Operation
+	var err error
+	switch {
+	case op.Zone != "":
+		result, err = manager.Service.ZoneOperations.Get(projectId, nameOfLink(op.Zone), op.Name).Context(ctx).Do()
+	case op.Region != "":
+		result, err = manager.Service.RegionOperations.Get(projectId, nameOfLink(op.Region), op.Name).Context(ctx).Do()
+	default:
+		result, err = manager.Service.GlobalOperations.Get(projectId, op.Name).Context(ctx).Do()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// finishOperation waits the operation if WaitUntilDone is in the options, the error is not wrapped
+func (manager *GceManager) finishOperation(
+	ctx context.Context, op *compute.Operation, opts []OperationOption) (*compute.Operation, error) {
+
+	if options := manager.newOperationOptions(opts); !options.wait {
+		return op, nil
+	}
+
+	return manager.waitOperation(ctx, op)
+}
+
+// projectOfLink gets the project id from the url of a resource
+func projectOfLink(link string) string {
+	segments := strings.Split(link, "/")
+	for i := 0; i < len(segments)-1; i++ {
+		if segments[i] == "projects" {
+			return segments[i+1]
+		}
+	}
+
+	return ""
+}
+
+// nameOfLink gets the last segment of the url of a resource
+func nameOfLink(link string) string {
+	arr := strings.Split(link, "/")
+
+	return arr[len(arr)-1]
+}
@@ -0,0 +1,55 @@
+package gce_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/browny/gogoo/gce"
+	"github.com/browny/gogoo/gerror"
+	"github.com/browny/gogoo/gogootest"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	compute "google.golang.org/api/compute/v1"
+)
+
+func TestOperations(t *testing.T) {
+	server, g := gogootest.NewGoGoo()
+	defer server.Close()
+
+	server.AddInstance(fakeZone, &compute.Instance{Name: "instance-test"})
+	server.SetOperationPolls(2)
+
+	// The operation is returned as soon as it is accepted
+	op, err := g.StopVm(gogootest.ProjectId, fakeZone, "instance-test", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "RUNNING", op.Status)
+	assert.Nil(t, g.WaitOperation(context.Background(), op))
+
+	// The operation is waited till DONE
+	op, err = g.StartVm(gogootest.ProjectId, fakeZone, "instance-test", nil, gce.WaitUntilDone())
+	assert.Nil(t, err)
+	assert.Equal(t, "DONE", op.Status)
+	assert.Nil(t, g.DeleteVm(gogootest.ProjectId, fakeZone, "instance-test", gce.WaitUntilDone()))
+
+	// The error of the operation is surfaced
+	server.FailNextOperation("QUOTA_EXCEEDED", "Quota 'CPUS' exceeded.")
+	err = g.NewVm(gogootest.ProjectId, fakeZone, fakeVm("instance-test"))
+	assert.True(t, gerror.IsQuotaExceeded(err))
+	var opErr *gce.OperationError
+	assert.True(t, errors.As(err, &opErr))
+	assert.Equal(t, "QUOTA_EXCEEDED", opErr.Errors[0].Code)
+	assert.Equal(t, "insert", opErr.Operation.OperationType)
+	assert.Contains(t, err.Error(), "Quota 'CPUS' exceeded.")
+
+	// Waiting is aborted by the context
+	server.SetOperationPolls(1000)
+	server.AddInstance(fakeZone, &compute.Instance{Name: "instance-test"})
+	op, _ = g.ResetInstance(gogootest.ProjectId, fakeZone, "instance-test")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = g.WaitOperation(ctx, op)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.False(t, gerror.IsRetryable(err))
+}
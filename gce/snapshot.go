@@ -0,0 +1,373 @@
+package gce
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/cihub/seelog"
+	"golang.org/x/net/context"
+	compute "google.golang.org/api/compute/v1"
+)
+
+// SnapshotCreationTimeout is the time to wait for a new snapshot to be READY
+const SnapshotCreationTimeout = 10 * time.Minute
+
+// CreateSnapshot creates the snapshot of the disk, and blocks till the snapshot is READY.
+// The name of snapshot is required, and the other fields like labels and description are optional.
+// https://godoc.org/google.golang.org/api/compute/v1#DisksService.CreateSnapshot
+func (manager *GceManager) CreateSnapshot(
+	projectId, zone, diskName string, snapshot *compute.Snapshot) (*compute.Snapshot, error) {
+
+	return manager.CreateSnapshotContext(context.Background(), projectId, zone, diskName, snapshot)
+}
+
+// CreateSnapshotContext is CreateSnapshot with the context of the request
+func (manager *GceManager) CreateSnapshotContext(
+	ctx context.Context, projectId, zone, diskName string, snapshot *compute.Snapshot) (*compute.Snapshot, error) {
+
+	if snapshot == nil {
+		return nil, gceError("CreateSnapshot", fmt.Errorf("snapshot is nil"))
+	}
+
+	log.Tracef("Create snapshot: project[%s], zone[%s], disk[%s], snapshot[%s]",
+		projectId, zone, diskName, snapshot.Name)
+
+	op, err := manager.Service.Disks.CreateSnapshot(projectId, zone, diskName, snapshot).
+		RequestId(newRequestId()).Context(ctx).Do()
+	if err != nil {
+		return nil, gceError("CreateSnapshot", err)
+	}
+	if _, err := manager.waitOperation(ctx, op); err != nil {
+		return nil, gceError("CreateSnapshot", err)
+	}
+
+	err = manager.waitStatus(ctx, projectId, KindSnapshot, "", snapshot.Name, "READY",
+		orDefault(manager.Timeouts.SnapshotCreation, SnapshotCreationTimeout))
+	if err != nil {
+		return nil, gceError("CreateSnapshot", err)
+	}
+
+	created, err := manager.Service.Snapshots.Get(projectId, snapshot.Name).Context(ctx).Do()
+
+	return created, gceError("CreateSnapshot", err)
+}
+
+// DeleteSnapshot deletes the snapshot.
+// It blocks till the snapshot is not found if `WaitUntilDeleted` is given.
+// https://godoc.org/google.golang.org/api/compute/v1#SnapshotsService.Delete
+func (manager *GceManager) DeleteSnapshot(projectId, snapshotName string, opts ...OperationOption) error {
+	return manager.DeleteSnapshotContext(context.Background(), projectId, snapshotName, opts...)
+}
+
+// DeleteSnapshotContext is DeleteSnapshot with the context of the request
+func (manager *GceManager) DeleteSnapshotContext(
+	ctx context.Context, projectId, snapshotName string, opts ...OperationOption) error {
+
+	log.Tracef("Delete snapshot: project[%s], snapshot[%s]", projectId, snapshotName)
+
+	op, err := manager.Service.Snapshots.Delete(projectId, snapshotName).Context(ctx).Do()
+	if err != nil {
+		return gceError("DeleteSnapshot", err)
+	}
+	options := manager.newOperationOptions(opts)
+	if _, err = manager.finishOperation(ctx, op, opts); err != nil || !options.deleted {
+		return gceError("DeleteSnapshot", err)
+	}
+
+	err = manager.waitStatus(ctx, projectId, KindSnapshot, "", snapshotName, StatusNotFound, options.deletionTimeout)
+
+	return gceError("DeleteSnapshot", err)
+}
+
+// RetentionPolicy tells which snapshots to keep, a snapshot is kept if any rule keeps it.
+// The days and weeks are counted in UTC, and a week starts on Monday.
+type RetentionPolicy struct {
+	// KeepLast keeps the latest n snapshots
+	KeepLast int
+	// KeepDaily keeps the latest snapshot of each day in the last n days, including today
+	KeepDaily int
+	// KeepWeekly keeps the latest snapshot of each week in the last n weeks, including this week
+	KeepWeekly int
+	// DryRun reports the expired snapshots without deleting them
+	DryRun bool
+}
+
+// RetentionReport is the result of applying the retention policy, the snapshots are ordered from the latest
+type RetentionReport struct {
+	// Kept are the snapshots kept by the policy
+	Kept []*compute.Snapshot
+	// Expired are the snapshots deleted, or to be deleted in the dry run
+	Expired []*compute.Snapshot
+	// Errors are the failures to delete the expired snapshots by their names
+	Errors map[string]error
+	DryRun bool
+}
+
+// Err summarizes the failures of deletion, it is nil if all expired snapshots are deleted
+func (report *RetentionReport) Err() error {
+	if len(report.Errors) == 0 {
+		return nil
+	}
+
+	causes := []string{}
+	for _, snapshot := range report.Expired {
+		if err, ok := report.Errors[snapshot.Name]; ok {
+			causes = append(causes, fmt.Sprintf("%s: %v", snapshot.Name, err))
+		}
+	}
+
+	return fmt.Errorf("%d of %d snapshots fail to be deleted: %s",
+		len(report.Errors), len(report.Expired), strings.Join(causes, "; "))
+}
+
+// Plan splits the snapshots into the kept and the expired ones at the time now, both are ordered
+// from the latest. The snapshots which are not READY or have no valid creation time are never expired,
+// and they are neither kept nor counted by the rules.
+func (policy RetentionPolicy) Plan(snapshots []*compute.Snapshot, now time.Time) (kept, expired []*compute.Snapshot) {
+	type dated struct {
+		snapshot *compute.Snapshot
+		created  time.Time
+	}
+
+	candidates := []dated{}
+	for _, snapshot := range snapshots {
+		created, err := time.Parse(time.RFC3339, snapshot.CreationTimestamp)
+		if snapshot.Status != "READY" || err != nil {
+			continue
+		}
+		candidates = append(candidates, dated{snapshot: snapshot, created: created.UTC()})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].created.After(candidates[j].created)
+	})
+
+	today := truncateDay(now.UTC())
+	firstDay := today.AddDate(0, 0, 1-policy.KeepDaily)
+	firstWeek := startOfWeek(today).AddDate(0, 0, 7*(1-policy.KeepWeekly))
+
+	days, weeks := map[time.Time]bool{}, map[time.Time]bool{}
+	for i, candidate := range candidates {
+		day, week := truncateDay(candidate.created), startOfWeek(candidate.created)
+
+		keep := i < policy.KeepLast
+		if policy.KeepDaily > 0 && !day.Before(firstDay) && !days[day] {
+			days[day], keep = true, true
+		}
+		if policy.KeepWeekly > 0 && !week.Before(firstWeek) && !weeks[week] {
+			weeks[week], keep = true, true
+		}
+
+		if keep {
+			kept = append(kept, candidate.snapshot)
+		} else {
+			expired = append(expired, candidate.snapshot)
+		}
+	}
+
+	return kept, expired
+}
+
+// ApplyRetention applies the retention policy to the snapshots whose names start with prefix,
+// the expired ones are deleted unless it is a dry run. The policy keeping nothing is refused.
+// The failures of deletion are reported by RetentionReport rather than the returned error.
+func (manager *GceManager) ApplyRetention(projectId, prefix string, policy RetentionPolicy) (*RetentionReport, error) {
+	return manager.ApplyRetentionContext(context.Background(), projectId, prefix, policy)
+}
+
+// ApplyRetentionContext is ApplyRetention with the context of the requests
+func (manager *GceManager) ApplyRetentionContext(
+	ctx context.Context, projectId, prefix string, policy RetentionPolicy) (*RetentionReport, error) {
+
+	log.Tracef("Apply retention: project[%s], prefix[%s], policy[%+v]", projectId, prefix, policy)
+
+	if policy.KeepLast <= 0 && policy.KeepDaily <= 0 && policy.KeepWeekly <= 0 {
+		return nil, gceError("ApplyRetention", fmt.Errorf("retention policy keeps no snapshot: %+v", policy))
+	}
+
+	filter := NewFilter().NameMatches(regexp.QuoteMeta(prefix) + ".*")
+	snapshots, err := manager.GetSnapshotsContext(ctx, projectId, WithFilter(filter))
+	if err != nil {
+		return nil, gceError("ApplyRetention", err)
+	}
+	matched := []*compute.Snapshot{}
+	for _, snapshot := range snapshots {
+		if strings.HasPrefix(snapshot.Name, prefix) {
+			matched = append(matched, snapshot)
+		}
+	}
+
+	report := &RetentionReport{Errors: map[string]error{}, DryRun: policy.DryRun}
+	report.Kept, report.Expired = policy.Plan(matched, time.Now())
+	for _, snapshot := range report.Expired {
+		if policy.DryRun {
+			log.Infof("Snapshot expired (dry run): %s", snapshot.Name)
+			continue
+		}
+
+		log.Infof("Snapshot expired: %s", snapshot.Name)
+		if err := manager.DeleteSnapshotContext(ctx, projectId, snapshot.Name, WaitUntilDone()); err != nil {
+			report.Errors[snapshot.Name] = err
+		}
+	}
+
+	return report, nil
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// startOfWeek returns the Monday of the week
+func startOfWeek(t time.Time) time.Time {
+	day := truncateDay(t)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// SnapshotNotFoundError tells the criteria selecting no snapshot, it is wrapped by *gerror.Error
+// with ReasonNotFound
+type SnapshotNotFoundError struct {
+	Criteria string
+}
+
+func (e *SnapshotNotFoundError) Error() string {
+	return fmt.Sprintf("no snapshot found: %s", e.Criteria)
+}
+
+// SnapshotOption narrows down the snapshots selected by GetLatestSnapshot
+type SnapshotOption func(*snapshotSelector)
+
+type snapshotSelector struct {
+	prefix     string
+	regex      string
+	selector   string
+	sourceDisk string
+	anyStatus  bool
+
+	pattern      *regexp.Regexp
+	requirements []labelRequirement
+}
+
+// WithNameRegex selects the snapshots whose whole names match the RE2 regular expression
+func WithNameRegex(regex string) SnapshotOption {
+	return func(s *snapshotSelector) {
+		s.regex = regex
+	}
+}
+
+// WithLabelSelector selects the snapshots by the label selector, see ParseLabelSelector for the syntax
+func WithLabelSelector(selector string) SnapshotOption {
+	return func(s *snapshotSelector) {
+		s.selector = selector
+	}
+}
+
+// WithSourceDisk selects the snapshots of the disk, which is the name, the partial url like
+// "zones/{zone}/disks/{disk}", or the full url of the disk
+func WithSourceDisk(disk string) SnapshotOption {
+	return func(s *snapshotSelector) {
+		s.sourceDisk = disk
+	}
+}
+
+// WithAnyStatus selects the snapshots in any status, only the READY ones are selected by default
+func WithAnyStatus() SnapshotOption {
+	return func(s *snapshotSelector) {
+		s.anyStatus = true
+	}
+}
+
+func newSnapshotSelector(prefix string, opts []SnapshotOption) (*snapshotSelector, error) {
+	s := &snapshotSelector{prefix: prefix}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.regex != "" {
+		pattern, err := regexp.Compile("^(?:" + s.regex + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid name regex[%s]: %w", s.regex, err)
+		}
+		s.pattern = pattern
+	}
+
+	requirements, err := parseLabelRequirements(s.selector)
+	if err != nil {
+		return nil, err
+	}
+	s.requirements = requirements
+
+	return s, nil
+}
+
+// match tells whether the snapshot is selected, and returns its creation time. The snapshot having
+// no valid creation time is never selected.
+func (s *snapshotSelector) match(snapshot *compute.Snapshot) (time.Time, bool) {
+	if !strings.HasPrefix(snapshot.Name, s.prefix) {
+		return time.Time{}, false
+	}
+	if s.pattern != nil && !s.pattern.MatchString(snapshot.Name) {
+		return time.Time{}, false
+	}
+	if !s.anyStatus && snapshot.Status != "READY" {
+		return time.Time{}, false
+	}
+	if !matchLabels(s.requirements, snapshot.Labels) {
+		return time.Time{}, false
+	}
+	if s.sourceDisk != "" && snapshot.SourceDisk != s.sourceDisk &&
+		!strings.HasSuffix(snapshot.SourceDisk, "/"+strings.TrimPrefix(s.sourceDisk, "/")) {
+		return time.Time{}, false
+	}
+
+	created, err := time.Parse(time.RFC3339, snapshot.CreationTimestamp)
+	if err != nil {
+		log.Warnf("Invalid creation time of snapshot[%s]: %v", snapshot.Name, err)
+		return time.Time{}, false
+	}
+
+	return created, true
+}
+
+func (s *snapshotSelector) String() string {
+	criteria := []string{fmt.Sprintf("prefix[%s]", s.prefix)}
+	if s.regex != "" {
+		criteria = append(criteria, fmt.Sprintf("regex[%s]", s.regex))
+	}
+	if s.selector != "" {
+		criteria = append(criteria, fmt.Sprintf("labels[%s]", s.selector))
+	}
+	if s.sourceDisk != "" {
+		criteria = append(criteria, fmt.Sprintf("sourceDisk[%s]", s.sourceDisk))
+	}
+	if !s.anyStatus {
+		criteria = append(criteria, "status[READY]")
+	}
+
+	return strings.Join(criteria, ", ")
+}
+
+// FindLatestSnapshot lists the snapshots of the project whose names start with prefix, and gets
+// the latest one by GetLatestSnapshot
+func (manager *GceManager) FindLatestSnapshot(
+	projectId, prefix string, opts ...SnapshotOption) (*compute.Snapshot, error) {
+
+	return manager.FindLatestSnapshotContext(context.Background(), projectId, prefix, opts...)
+}
+
+// FindLatestSnapshotContext is FindLatestSnapshot with the context of the requests
+func (manager *GceManager) FindLatestSnapshotContext(
+	ctx context.Context, projectId, prefix string, opts ...SnapshotOption) (*compute.Snapshot, error) {
+
+	log.Tracef("Find latest snapshot: project[%s], prefix[%s]", projectId, prefix)
+
+	filter := NewFilter().NameMatches(regexp.QuoteMeta(prefix) + ".*")
+	snapshots, err := manager.GetSnapshotsContext(ctx, projectId, WithFilter(filter))
+	if err != nil {
+		return nil, err
+	}
+
+	return manager.GetLatestSnapshot(prefix, snapshots, opts...)
+}
@@ -0,0 +1,178 @@
+package gce_test
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/browny/gogoo/gce"
+	"github.com/browny/gogoo/gerror"
+	"github.com/browny/gogoo/gogootest"
+
+	"github.com/stretchr/testify/assert"
+	compute "google.golang.org/api/compute/v1"
+)
+
+func names(snapshots []*compute.Snapshot) []string {
+	result := []string{}
+	for _, snapshot := range snapshots {
+		result = append(result, snapshot.Name)
+	}
+
+	return result
+}
+
+func TestRetentionPolicy(t *testing.T) {
+	// Wednesday
+	now := time.Date(2016, 6, 15, 12, 0, 0, 0, time.UTC)
+	snapshot := func(name string, created time.Time) *compute.Snapshot {
+		return &compute.Snapshot{Name: name, Status: "READY", CreationTimestamp: created.Format(time.RFC3339)}
+	}
+	snapshots := []*compute.Snapshot{
+		snapshot("s-0615-a", now.Add(-1*time.Hour)),
+		snapshot("s-0615-b", now.Add(-2*time.Hour)),
+		snapshot("s-0614", now.AddDate(0, 0, -1)),
+		snapshot("s-0613", now.AddDate(0, 0, -2)),
+		snapshot("s-0612", now.AddDate(0, 0, -3)),
+		snapshot("s-0608", now.AddDate(0, 0, -7)),
+		snapshot("s-0601", now.AddDate(0, 0, -14)),
+		snapshot("s-0525", now.AddDate(0, 0, -21)),
+		{Name: "s-creating", Status: "CREATING", CreationTimestamp: now.AddDate(0, 0, -30).Format(time.RFC3339)},
+		{Name: "s-unknown", Status: "READY"},
+	}
+
+	kept, expired := gce.RetentionPolicy{KeepLast: 3}.Plan(snapshots, now)
+	assert.Equal(t, []string{"s-0615-a", "s-0615-b", "s-0614"}, names(kept))
+	assert.Equal(t, []string{"s-0613", "s-0612", "s-0608", "s-0601", "s-0525"}, names(expired))
+
+	kept, _ = gce.RetentionPolicy{KeepDaily: 3}.Plan(snapshots, now)
+	assert.Equal(t, []string{"s-0615-a", "s-0614", "s-0613"}, names(kept))
+
+	// The weeks start on 6/13 (this week), 6/6 and 5/30
+	kept, _ = gce.RetentionPolicy{KeepWeekly: 3}.Plan(snapshots, now)
+	assert.Equal(t, []string{"s-0615-a", "s-0612", "s-0601"}, names(kept))
+
+	kept, expired = gce.RetentionPolicy{KeepLast: 1, KeepDaily: 2, KeepWeekly: 4}.Plan(snapshots, now)
+	assert.Equal(t, []string{"s-0615-a", "s-0614", "s-0612", "s-0601", "s-0525"}, names(kept))
+	assert.Equal(t, []string{"s-0615-b", "s-0613", "s-0608"}, names(expired))
+
+	kept, expired = gce.RetentionPolicy{}.Plan(snapshots, now)
+	assert.Empty(t, kept)
+	assert.Len(t, expired, 8)
+}
+
+func TestSnapshot(t *testing.T) {
+	server, g := gogootest.NewGoGoo()
+	defer server.Close()
+
+	server.AddDisk(fakeZone, &compute.Disk{Name: "disk-test", SizeGb: 10})
+	server.SetStatusTransitions(true)
+
+	snapshot, err := g.CreateSnapshot(gogootest.ProjectId, fakeZone, "disk-test",
+		&compute.Snapshot{Name: "snapshot-test", Labels: map[string]string{"env": "test"}})
+	assert.Nil(t, err)
+	assert.Equal(t, "READY", snapshot.Status)
+	assert.Equal(t, "test", snapshot.Labels["env"])
+	assert.Equal(t, int64(10), snapshot.DiskSizeGb)
+	assert.True(t, strings.HasSuffix(snapshot.SourceDisk, "/disks/disk-test"))
+
+	_, err = g.CreateSnapshot(gogootest.ProjectId, fakeZone, "disk-not-existed", &compute.Snapshot{Name: "snapshot-x"})
+	assert.True(t, gerror.IsNotFound(err))
+	_, err = g.CreateSnapshot(gogootest.ProjectId, fakeZone, "disk-test", nil)
+	assert.NotNil(t, err)
+
+	server.SetDeletionPolls(2)
+	assert.Nil(t, g.DeleteSnapshot(gogootest.ProjectId, "snapshot-test", gce.WaitUntilDeleted(0)))
+	_, err = g.GetSnapshot(gogootest.ProjectId, "snapshot-test")
+	assert.True(t, gerror.IsNotFound(err))
+	server.SetDeletionPolls(0)
+
+	now := time.Now().UTC()
+	for i := 0; i < 5; i++ {
+		server.AddSnapshot(&compute.Snapshot{
+			Name:              fmt.Sprintf("daily-%d", i),
+			Status:            "READY",
+			CreationTimestamp: now.AddDate(0, 0, -i).Format(time.RFC3339),
+		})
+	}
+	server.AddSnapshot(&compute.Snapshot{Name: "other", Status: "READY", CreationTimestamp: now.AddDate(-1, 0, 0).Format(time.RFC3339)})
+
+	_, err = g.ApplyRetention(gogootest.ProjectId, "daily-", gce.RetentionPolicy{DryRun: true})
+	assert.NotNil(t, err)
+
+	report, err := g.ApplyRetention(gogootest.ProjectId, "daily-", gce.RetentionPolicy{KeepLast: 2, DryRun: true})
+	assert.Nil(t, err)
+	assert.True(t, report.DryRun)
+	assert.Len(t, report.Kept, 2)
+	assert.Len(t, report.Expired, 3)
+	snapshots, _ := g.GetSnapshots(gogootest.ProjectId)
+	assert.Len(t, snapshots, 6)
+
+	report, err = g.ApplyRetention(gogootest.ProjectId, "daily-", gce.RetentionPolicy{KeepLast: 2})
+	assert.Nil(t, err)
+	assert.Nil(t, report.Err())
+	assert.Equal(t, "daily-2", report.Expired[0].Name)
+	snapshots, _ = g.GetSnapshots(gogootest.ProjectId)
+	sort.Sort(gce.BySnapshotName(snapshots))
+	assert.Len(t, snapshots, 3)
+	assert.Equal(t, "daily-0", snapshots[0].Name)
+	assert.Equal(t, "daily-1", snapshots[1].Name)
+	assert.Equal(t, "other", snapshots[2].Name)
+}
+
+func TestLatestSnapshot(t *testing.T) {
+	server, g := gogootest.NewGoGoo()
+	defer server.Close()
+
+	diskLink := "https://www.googleapis.com/compute/v1/projects/gogootest/zones/asia-east1-b/disks/"
+	at := func(day int) string {
+		return time.Date(2016, 6, day, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+	}
+	for _, snapshot := range []*compute.Snapshot{
+		// The names are not ordered by the creation time
+		{Name: "db-b", CreationTimestamp: at(1), SourceDisk: diskLink + "disk-db", Labels: map[string]string{"env": "prod"}},
+		{Name: "db-a", CreationTimestamp: at(3), SourceDisk: diskLink + "disk-db", Labels: map[string]string{"env": "test"}},
+		{Name: "db-c", CreationTimestamp: at(2), SourceDisk: diskLink + "disk-other"},
+		{Name: "db-creating", CreationTimestamp: at(4), Status: "CREATING"},
+		{Name: "mydb-z", CreationTimestamp: at(5)},
+	} {
+		server.AddSnapshot(snapshot)
+	}
+
+	latest, err := g.FindLatestSnapshot(gogootest.ProjectId, "db-")
+	assert.Nil(t, err)
+	assert.Equal(t, "db-a", latest.Name)
+
+	latest, err = g.FindLatestSnapshot(gogootest.ProjectId, "db-", gce.WithAnyStatus())
+	assert.Nil(t, err)
+	assert.Equal(t, "db-creating", latest.Name)
+
+	latest, err = g.FindLatestSnapshot(gogootest.ProjectId, "db-", gce.WithLabelSelector("env=prod"))
+	assert.Nil(t, err)
+	assert.Equal(t, "db-b", latest.Name)
+
+	latest, err = g.FindLatestSnapshot(gogootest.ProjectId, "db-", gce.WithLabelSelector("env!=test"))
+	assert.Nil(t, err)
+	assert.Equal(t, "db-c", latest.Name)
+
+	latest, err = g.FindLatestSnapshot(gogootest.ProjectId, "", gce.WithSourceDisk("zones/asia-east1-b/disks/disk-other"))
+	assert.Nil(t, err)
+	assert.Equal(t, "db-c", latest.Name)
+
+	latest, err = g.FindLatestSnapshot(gogootest.ProjectId, "", gce.WithNameRegex("(db|mydb)-[bz]"))
+	assert.Nil(t, err)
+	assert.Equal(t, "mydb-z", latest.Name)
+
+	_, err = g.FindLatestSnapshot(gogootest.ProjectId, "db-", gce.WithSourceDisk("disk-db"), gce.WithNameRegex("db-c"))
+	assert.True(t, gerror.IsNotFound(err))
+	var notFound *gce.SnapshotNotFoundError
+	assert.True(t, errors.As(err, &notFound))
+	assert.Equal(t, "prefix[db-], regex[db-c], sourceDisk[disk-db], status[READY]", notFound.Criteria)
+
+	_, err = g.FindLatestSnapshot(gogootest.ProjectId, "db-", gce.WithNameRegex("("))
+	assert.NotNil(t, err)
+	assert.False(t, gerror.IsNotFound(err))
+}
@@ -0,0 +1,159 @@
+package gce
+
+import (
+	"github.com/browny/gogoo/gerror"
+	"github.com/browny/gogoo/utility"
+
+	log "github.com/cihub/seelog"
+	"golang.org/x/net/context"
+	compute "google.golang.org/api/compute/v1"
+)
+
+// FingerprintMaxAttempts is the max number of attempts to update the tags, labels or metadata of
+// a VM, or the named ports of an instance group, which are retried when they are changed by others
+// at the same time
+const FingerprintMaxAttempts = 5
+
+// TagChange is the change of the network tags of a VM
+type TagChange struct {
+	// Set replaces all tags if it is not nil, and Add and Remove are applied after it
+	Set    []string
+	Add    []string
+	Remove []string
+}
+
+// apply applies the change to the tags, the result is deduplicated and keeps the order of tags
+func (change TagChange) apply(tags []string) []string {
+	if change.Set != nil {
+		tags = change.Set
+	}
+
+	result := []string{}
+	for _, tag := range append(append([]string{}, tags...), change.Add...) {
+		if !utility.InStringSlice(result, tag) && !utility.InStringSlice(change.Remove, tag) {
+			result = append(result, tag)
+		}
+	}
+
+	return result
+}
+
+// updateVm reads the VM and calls update, which sends the request with the fingerprint read, so the
+// changes made by others meanwhile are not overwritten. On the mismatch of fingerprint, it reads the VM
+// and calls update again, at most `FingerprintMaxAttempts` times. The error is not wrapped.
+func (manager *GceManager) updateVm(ctx context.Context, projectId, zone, vmName string,
+	update func(vm *compute.Instance) (*compute.Operation, error)) (*compute.Operation, error) {
+
+	for attempt := 1; ; attempt++ {
+		vm, err := manager.GetVmContext(ctx, projectId, zone, vmName)
+		if err != nil {
+			return nil, err
+		}
+
+		op, err := update(vm)
+		if gerror.IsPreconditionFailed(err) && attempt < FingerprintMaxAttempts {
+			log.Debugf("VM[%s] is changed meanwhile, attempt[%d]", vmName, attempt)
+			continue
+		}
+
+		return op, err
+	}
+}
+
+// adjustTags applies the change to the tags of VM by updateVm
+// https://godoc.org/google.golang.org/api/compute/v1#InstancesService.SetTags
+func (manager *GceManager) adjustTags(
+	ctx context.Context, projectId, zone, vmName string, change TagChange, opts []OperationOption) (
+	*compute.Operation, error) {
+
+	op, err := manager.updateVm(ctx, projectId, zone, vmName, func(vm *compute.Instance) (*compute.Operation, error) {
+		tags := &compute.Tags{}
+		if vm.Tags != nil {
+			tags.Items, tags.Fingerprint = vm.Tags.Items, vm.Tags.Fingerprint
+		}
+		tags.Items = change.apply(tags.Items)
+
+		return manager.Service.Instances.SetTags(projectId, zone, vmName, tags).Context(ctx).Do()
+	})
+	if err != nil {
+		return nil, gceError("SetTags", err)
+	}
+	op, err = manager.finishOperation(ctx, op, opts)
+
+	return op, gceError("SetTags", err)
+}
+
+// AttachTags attaches tags onto VM, the tags already attached are not duplicated.
+func (manager *GceManager) AttachTags(
+	projectId, zone, vmName string, addedTags []string, opts ...OperationOption) (*compute.Operation, error) {
+
+	return manager.AttachTagsContext(context.Background(), projectId, zone, vmName, addedTags, opts...)
+}
+
+// AttachTagsContext is AttachTags with the context of the requests
+func (manager *GceManager) AttachTagsContext(
+	ctx context.Context, projectId, zone, vmName string, addedTags []string, opts ...OperationOption) (
+	*compute.Operation, error) {
+
+	log.Tracef("AttachTags: vm[%s], addedTags[%s]", vmName, addedTags)
+
+	return manager.adjustTags(ctx, projectId, zone, vmName, TagChange{Add: addedTags}, opts)
+}
+
+// DetachTags detaches tags from VM.
+func (manager *GceManager) DetachTags(
+	projectId, zone, vmName string, removedTages []string, opts ...OperationOption) (*compute.Operation, error) {
+
+	return manager.DetachTagsContext(context.Background(), projectId, zone, vmName, removedTages, opts...)
+}
+
+// DetachTagsContext is DetachTags with the context of the requests
+func (manager *GceManager) DetachTagsContext(
+	ctx context.Context, projectId, zone, vmName string, removedTages []string, opts ...OperationOption) (
+	*compute.Operation, error) {
+
+	log.Tracef("DetachTags: vm[%s], removedTages[%s]", vmName, removedTages)
+
+	return manager.adjustTags(ctx, projectId, zone, vmName, TagChange{Remove: removedTages}, opts)
+}
+
+// ReplaceTags replaces all tags of VM by the tags, which are deduplicated.
+func (manager *GceManager) ReplaceTags(
+	projectId, zone, vmName string, tags []string, opts ...OperationOption) (*compute.Operation, error) {
+
+	return manager.ReplaceTagsContext(context.Background(), projectId, zone, vmName, tags, opts...)
+}
+
+// ReplaceTagsContext is ReplaceTags with the context of the requests
+func (manager *GceManager) ReplaceTagsContext(
+	ctx context.Context, projectId, zone, vmName string, tags []string, opts ...OperationOption) (
+	*compute.Operation, error) {
+
+	log.Tracef("ReplaceTags: vm[%s], tags[%s]", vmName, tags)
+
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return manager.adjustTags(ctx, projectId, zone, vmName, TagChange{Set: tags}, opts)
+}
+
+// SetTagsOnMany applies the change to the tags of the VMs in parallel, it blocks till the SetTags
+// operations of all VMs are DONE or fail. The result of each VM is in the report.
+func (manager *GceManager) SetTagsOnMany(
+	projectId string, vms []VmRef, change TagChange, opts ...FleetOption) *FleetReport {
+
+	return manager.SetTagsOnManyContext(context.Background(), projectId, vms, change, opts...)
+}
+
+// SetTagsOnManyContext is SetTagsOnMany with the context of the requests
+func (manager *GceManager) SetTagsOnManyContext(
+	ctx context.Context, projectId string, vms []VmRef, change TagChange, opts ...FleetOption) *FleetReport {
+
+	log.Tracef("Set tags on VMs: project[%s], count[%d], change[%+v]", projectId, len(vms), change)
+
+	return runFleet(ctx, vms, opts, func(ctx context.Context, i int) error {
+		_, err := manager.adjustTags(ctx, projectId, vms[i].Zone, vms[i].Name, change, []OperationOption{WaitUntilDone()})
+		return err
+	})
+}
@@ -0,0 +1,62 @@
+package gce_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/browny/gogoo/gce"
+	"github.com/browny/gogoo/gerror"
+	"github.com/browny/gogoo/gogootest"
+
+	"github.com/stretchr/testify/assert"
+	compute "google.golang.org/api/compute/v1"
+)
+
+func TestTags(t *testing.T) {
+	server, g := gogootest.NewGoGoo()
+	defer server.Close()
+
+	vm := fakeVm("instance-test")
+	vm.Tags = &compute.Tags{Items: []string{"http-server"}}
+	server.AddInstance(fakeZone, vm)
+
+	_, err := g.AttachTags(gogootest.ProjectId, fakeZone, "instance-test", []string{"http-server", "a", "a"})
+	assert.Nil(t, err)
+	vm, _ = g.GetVm(gogootest.ProjectId, fakeZone, "instance-test")
+	assert.Equal(t, []string{"http-server", "a"}, vm.Tags.Items)
+
+	// The concurrent updates retry on the mismatch of fingerprint, none of them is lost
+	var wg sync.WaitGroup
+	for i := 0; i < gce.FingerprintMaxAttempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := g.AttachTags(gogootest.ProjectId, fakeZone, "instance-test", []string{fmt.Sprintf("tag-%d", i)})
+			assert.Nil(t, err)
+		}(i)
+	}
+	wg.Wait()
+	vm, _ = g.GetVm(gogootest.ProjectId, fakeZone, "instance-test")
+	assert.Len(t, vm.Tags.Items, 2+gce.FingerprintMaxAttempts)
+
+	_, err = g.ReplaceTags(gogootest.ProjectId, fakeZone, "instance-test", []string{"b", "c", "b"})
+	assert.Nil(t, err)
+	vm, _ = g.GetVm(gogootest.ProjectId, fakeZone, "instance-test")
+	assert.Equal(t, []string{"b", "c"}, vm.Tags.Items)
+
+	server.AddInstance(fakeZone, fakeVm("instance-other"))
+	report := g.SetTagsOnMany(gogootest.ProjectId, []gce.VmRef{
+		{Zone: fakeZone, Name: "instance-test"},
+		{Zone: fakeZone, Name: "instance-other"},
+		{Zone: fakeZone, Name: "instance-not-existed"},
+	}, gce.TagChange{Add: []string{"d"}, Remove: []string{"b"}})
+	assert.Len(t, report.Succeeded(), 2)
+	assert.Len(t, report.Failed(), 1)
+	assert.True(t, gerror.IsNotFound(report.Failed()[0].Err))
+
+	vm, _ = g.GetVm(gogootest.ProjectId, fakeZone, "instance-test")
+	assert.Equal(t, []string{"c", "d"}, vm.Tags.Items)
+	vm, _ = g.GetVm(gogootest.ProjectId, fakeZone, "instance-other")
+	assert.Equal(t, []string{"d"}, vm.Tags.Items)
+}
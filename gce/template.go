@@ -0,0 +1,351 @@
+package gce
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	compute "google.golang.org/api/compute/v1"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Stages of rendering a VM template, which are reported by TemplateError
+const (
+	TemplateParse    = "parse"
+	TemplateExecute  = "execute"
+	TemplateDecode   = "decode"
+	TemplateValidate = "validate"
+)
+
+var templateLinePattern = regexp.MustCompile(`^template: .*?:(\d+)(:\d+)?:`)
+
+// TemplateError is the error of a VM template. The line of parse and execute errors is the
+// line of the template, and the line of decode errors is the line of the rendered document.
+type TemplateError struct {
+	// Name is the name of the template
+	Name string
+	// Stage is where it fails, i.e. TemplateParse, TemplateExecute, TemplateDecode or TemplateValidate
+	Stage string
+	// Line is the line number starting from 1, 0 if unknown
+	Line int
+	// Context is the content of the line
+	Context string
+	Err     error
+}
+
+func (e *TemplateError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("vm template[%s] %s: %v", e.Name, e.Stage, e.Err)
+	}
+
+	return fmt.Sprintf("vm template[%s] %s: line %d: `%s`: %v", e.Name, e.Stage, e.Line, e.Context, e.Err)
+}
+
+// Unwrap returns the cause
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// VmTemplate renders compute.Instance from a text/template of JSON or YAML document, e.g.
+//
+//	name: {{.Name}}
+//	machineType: zones/{{.Zone}}/machineTypes/{{.MachineType}}
+//	labels:
+//	  env: {{.Env}}
+//	metadata:
+//	  items:
+//	  - key: startup-script
+//	    value: {{json .StartupScript}}
+//
+// The template can use the function `json` to quote any value, e.g. a multiline script.
+// Referring to a missing parameter is an error.
+type VmTemplate struct {
+	name     string
+	text     string
+	template *template.Template
+}
+
+// ParseVmTemplate parses the VM template, name is used in the error messages, e.g. the file name
+func ParseVmTemplate(name string, text []byte) (*VmTemplate, error) {
+	tmpl, err := template.New(name).
+		Option("missingkey=error").
+		Funcs(template.FuncMap{"json": toJson}).
+		Parse(string(text))
+	if err != nil {
+		return nil, templateError(name, TemplateParse, string(text), err)
+	}
+
+	return &VmTemplate{name: name, text: string(text), template: tmpl}, nil
+}
+
+// Render executes the template with the parameters, which are a struct or a map, decodes the
+// rendered document by its format, JSON if it starts with '{' or YAML otherwise, and validates
+// the VM by ValidateVm.
+// The error is *TemplateError telling the stage and line which fail.
+func (t *VmTemplate) Render(params interface{}) (*compute.Instance, error) {
+	vm, err := t.execute(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ValidateVm(vm); err != nil {
+		return nil, &TemplateError{Name: t.name, Stage: TemplateValidate, Err: err}
+	}
+
+	return vm, nil
+}
+
+// execute is Render without validation
+func (t *VmTemplate) execute(params interface{}) (*compute.Instance, error) {
+	var b bytes.Buffer
+	if err := t.template.Execute(&b, params); err != nil {
+		return nil, templateError(t.name, TemplateExecute, t.text, err)
+	}
+
+	vm, err := decodeVm(b.Bytes())
+	if err != nil {
+		return nil, err.(*TemplateError).in(t.name, b.String())
+	}
+
+	return vm, nil
+}
+
+// ValidateVm checks the fields required to create the VM: name, machine type, a boot disk
+// from an image or an existing disk, and a network interface. The labels are checked as well.
+func ValidateVm(vm *compute.Instance) error {
+	if vm == nil {
+		return fmt.Errorf("vm is nil")
+	}
+
+	problems := []string{}
+	if vm.Name == "" {
+		problems = append(problems, "name is required")
+	}
+	if vm.MachineType == "" {
+		problems = append(problems, "machineType is required")
+	}
+
+	boots := 0
+	for i, disk := range vm.Disks {
+		if disk == nil {
+			problems = append(problems, fmt.Sprintf("disks[%d] is empty", i))
+			continue
+		}
+		if disk.Boot {
+			boots++
+		}
+		if disk.Source == "" && (disk.InitializeParams == nil || (disk.Boot && disk.InitializeParams.SourceImage == "")) {
+			problems = append(problems, fmt.Sprintf("disks[%d] needs source, or initializeParams with sourceImage", i))
+		}
+	}
+	if boots != 1 {
+		problems = append(problems, fmt.Sprintf("exactly one boot disk is required, got %d", boots))
+	}
+
+	if len(vm.NetworkInterfaces) == 0 {
+		problems = append(problems, "networkInterfaces is required")
+	}
+	for i, nic := range vm.NetworkInterfaces {
+		if nic == nil || (nic.Network == "" && nic.Subnetwork == "") {
+			problems = append(problems, fmt.Sprintf("networkInterfaces[%d] needs network or subnetwork", i))
+		}
+	}
+
+	for key, value := range vm.Labels {
+		if !labelKeyPattern.MatchString(key) || !labelValuePattern.MatchString(value) {
+			problems = append(problems, fmt.Sprintf("invalid label %s=%s", key, value))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid vm[%s]: %s", vm.Name, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// decodeVm decodes the JSON or YAML document into the VM, the error is *TemplateError
+// without the name of template and the line context
+func decodeVm(document []byte) (*compute.Instance, error) {
+	var generic interface{}
+	if bytes.HasPrefix(bytes.TrimSpace(document), []byte("{")) {
+		decoder := json.NewDecoder(bytes.NewReader(document))
+		decoder.UseNumber()
+		if err := decoder.Decode(&generic); err != nil {
+			line := 0
+			if syntaxErr, ok := err.(*json.SyntaxError); ok {
+				line = lineOfOffset(document, syntaxErr.Offset)
+			}
+			return nil, &TemplateError{Stage: TemplateDecode, Line: line, Err: err}
+		}
+	} else {
+		if err := yaml.Unmarshal(document, &generic); err != nil {
+			return nil, &TemplateError{Stage: TemplateDecode, Line: lineOfYamlError(err), Err: err}
+		}
+		generic = fromYaml(generic)
+	}
+
+	b, err := json.Marshal(normalize(generic, reflect.TypeOf(compute.Instance{})))
+	if err != nil {
+		return nil, &TemplateError{Stage: TemplateDecode, Err: err}
+	}
+
+	vm := &compute.Instance{}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(vm); err != nil {
+		return nil, &TemplateError{Stage: TemplateDecode, Line: lineOfField(document, err), Err: err}
+	}
+
+	return vm, nil
+}
+
+// in fills the name of template and the line context of the document
+func (e *TemplateError) in(name, document string) *TemplateError {
+	e.Name = name
+	if lines := strings.Split(document, "\n"); e.Line > 0 && e.Line <= len(lines) {
+		e.Context = strings.TrimSpace(lines[e.Line-1])
+	}
+
+	return e
+}
+
+// templateError builds the error of parsing or executing template, the line is taken from the message
+func templateError(name, stage, text string, err error) *TemplateError {
+	e := &TemplateError{Stage: stage, Err: err}
+	if m := templateLinePattern.FindStringSubmatch(err.Error()); m != nil {
+		e.Line, _ = strconv.Atoi(m[1])
+	}
+
+	return e.in(name, text)
+}
+
+func lineOfOffset(document []byte, offset int64) int {
+	if offset > int64(len(document)) {
+		offset = int64(len(document))
+	}
+
+	return bytes.Count(document[:offset], []byte("\n")) + 1
+}
+
+var yamlLinePattern = regexp.MustCompile(`line (\d+):`)
+
+func lineOfYamlError(err error) int {
+	if m := yamlLinePattern.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return line
+	}
+
+	return 0
+}
+
+var jsonFieldPattern = regexp.MustCompile(`unknown field "([^"]+)"|Go struct field [\w.]*?(\w+) of type`)
+
+// lineOfField finds the first line of the document mentioning the field in the error
+func lineOfField(document []byte, err error) int {
+	m := jsonFieldPattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return 0
+	}
+	field := m[1] + m[2]
+
+	for i, line := range strings.Split(string(document), "\n") {
+		if strings.Contains(strings.ToLower(line), strings.ToLower(field)) {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// fromYaml converts the maps decoded by yaml into JSON objects
+func fromYaml(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		object := map[string]interface{}{}
+		for key, element := range v {
+			object[fmt.Sprint(key)] = fromYaml(element)
+		}
+		return object
+	case []interface{}:
+		for i, element := range v {
+			v[i] = fromYaml(element)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// normalize quotes the scalars of the fields encoded as JSON strings, e.g. `diskSizeGb`,
+// so the numbers in the templates are accepted
+func normalize(value interface{}, t reflect.Type) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		switch t.Kind() {
+		case reflect.Struct:
+			for key, element := range v {
+				if field, quoted, ok := jsonField(t, key); ok {
+					if quoted {
+						if _, isString := element.(string); !isString && element != nil {
+							element = fmt.Sprint(element)
+						}
+					}
+					v[key] = normalize(element, field.Type)
+				}
+			}
+		case reflect.Map:
+			for key, element := range v {
+				v[key] = normalize(element, t.Elem())
+			}
+		}
+		return v
+	case []interface{}:
+		if t.Kind() == reflect.Slice {
+			for i, element := range v {
+				v[i] = normalize(element, t.Elem())
+			}
+		}
+		return v
+	case bool, json.Number, int, int64, float64:
+		if t.Kind() == reflect.String {
+			return fmt.Sprint(v)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// jsonField finds the field of struct t by its JSON name, quoted tells whether it has `,string`
+func jsonField(t reflect.Type, name string) (reflect.StructField, bool, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")
+		if tag[0] != name {
+			continue
+		}
+		for _, option := range tag[1:] {
+			if option == "string" {
+				return field, true, true
+			}
+		}
+		return field, false, true
+	}
+
+	return reflect.StructField{}, false, false
+}
+
+func toJson(value interface{}) (string, error) {
+	b, err := json.Marshal(value)
+	return string(b), err
+}
@@ -0,0 +1,146 @@
+package gce_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/browny/gogoo/gce"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const yamlVmTemplate = `name: {{.Name}}
+machineType: zones/{{.Zone}}/machineTypes/{{.MachineType}}
+labels:
+  env: {{.Env}}
+  build: {{.Build}}
+disks:
+- boot: true
+  autoDelete: true
+  initializeParams:
+    sourceImage: {{.Image}}
+    diskSizeGb: {{.DiskSizeGb}}
+networkInterfaces:
+- network: global/networks/{{.Network}}
+  accessConfigs:
+  - type: ONE_TO_ONE_NAT
+metadata:
+  items:
+  - key: startup-script
+    value: {{json .StartupScript}}
+`
+
+const jsonVmTemplate = `{
+  "name": "{{.Name}}",
+  "machineType": "zones/{{.Zone}}/machineTypes/n1-standard-1",
+  "disks": [{"boot": true, "initializeParams": {"sourceImage": "global/images/image-test", "diskSizeGb": "10"}}],
+  "networkInterfaces": [{"network": "global/networks/default"}]
+}`
+
+func yamlParams() map[string]interface{} {
+	return map[string]interface{}{
+		"Name":          "instance-test",
+		"Zone":          "asia-east1-b",
+		"MachineType":   "n1-standard-2",
+		"Env":           "prod",
+		"Build":         42,
+		"Image":         "global/images/image-test",
+		"DiskSizeGb":    20,
+		"Network":       "default",
+		"StartupScript": "#!/bin/bash\necho \"hello\"\n",
+	}
+}
+
+func TestVmTemplate(t *testing.T) {
+	tmpl, err := gce.ParseVmTemplate("vm.yaml", []byte(yamlVmTemplate))
+	assert.Nil(t, err)
+
+	vm, err := tmpl.Render(yamlParams())
+	assert.Nil(t, err)
+	assert.Equal(t, "instance-test", vm.Name)
+	assert.Equal(t, "zones/asia-east1-b/machineTypes/n1-standard-2", vm.MachineType)
+	assert.Equal(t, map[string]string{"env": "prod", "build": "42"}, vm.Labels)
+	assert.True(t, vm.Disks[0].Boot)
+	assert.Equal(t, int64(20), vm.Disks[0].InitializeParams.DiskSizeGb)
+	assert.Equal(t, "ONE_TO_ONE_NAT", vm.NetworkInterfaces[0].AccessConfigs[0].Type)
+	assert.Equal(t, "startup-script", vm.Metadata.Items[0].Key)
+	assert.Equal(t, "#!/bin/bash\necho \"hello\"\n", *vm.Metadata.Items[0].Value)
+
+	tmpl, err = gce.ParseVmTemplate("vm.json", []byte(jsonVmTemplate))
+	assert.Nil(t, err)
+	vm, err = tmpl.Render(struct{ Name, Zone string }{"instance-json", "asia-east1-b"})
+	assert.Nil(t, err)
+	assert.Equal(t, "instance-json", vm.Name)
+	assert.Equal(t, int64(10), vm.Disks[0].InitializeParams.DiskSizeGb)
+}
+
+func TestInitVmFromTemplateParams(t *testing.T) {
+	manager := &gce.GceManager{}
+
+	vm, err := manager.InitVmFromTemplateParams([]byte(yamlVmTemplate), yamlParams())
+	assert.Nil(t, err)
+	assert.Equal(t, "zones/asia-east1-b/machineTypes/n1-standard-2", vm.MachineType)
+
+	// The template referring to parameters other than Zone needs the params
+	_, err = manager.InitVmFromTemplate([]byte(yamlVmTemplate), "asia-east1-b")
+	var templateErr *gce.TemplateError
+	assert.True(t, errors.As(err, &templateErr))
+	assert.Equal(t, gce.TemplateExecute, templateErr.Stage)
+
+	// The VM of InitVmFromTemplate is completed by the caller, it is not validated
+	partial := []byte(`{"name": "instance-test", "machineType": "zones/{{.Zone}}/machineTypes/n1-standard-1"}`)
+	vm, err = manager.InitVmFromTemplate(partial, "asia-east1-b")
+	assert.Nil(t, err)
+	assert.Equal(t, "zones/asia-east1-b/machineTypes/n1-standard-1", vm.MachineType)
+
+	_, err = manager.InitVmFromTemplateParams(partial, map[string]interface{}{"Zone": "asia-east1-b"})
+	assert.True(t, errors.As(err, &templateErr))
+	assert.Equal(t, gce.TemplateValidate, templateErr.Stage)
+}
+
+func TestVmTemplateErrors(t *testing.T) {
+	var e *gce.TemplateError
+
+	_, err := gce.ParseVmTemplate("vm.yaml", []byte("name: test\nmachineType: {{zone .Zone}}\n"))
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, gce.TemplateParse, e.Stage)
+	assert.Equal(t, 2, e.Line)
+	assert.Equal(t, "machineType: {{zone .Zone}}", e.Context)
+
+	tmpl, _ := gce.ParseVmTemplate("vm.yaml", []byte(yamlVmTemplate))
+	params := yamlParams()
+	delete(params, "Image")
+	_, err = tmpl.Render(params)
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, gce.TemplateExecute, e.Stage)
+	assert.Equal(t, 10, e.Line)
+	assert.Contains(t, e.Error(), "sourceImage: {{.Image}}")
+
+	tmpl, _ = gce.ParseVmTemplate("vm.yaml", []byte("name: test\n  machineType: [\n"))
+	_, err = tmpl.Render(nil)
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, gce.TemplateDecode, e.Stage)
+	assert.NotZero(t, e.Line)
+
+	tmpl, _ = gce.ParseVmTemplate("vm.json", []byte("{\n  \"name\": \"test\",\n  \"machineType\" \"n1\"\n}"))
+	_, err = tmpl.Render(nil)
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, gce.TemplateDecode, e.Stage)
+	assert.Equal(t, 3, e.Line)
+
+	tmpl, _ = gce.ParseVmTemplate("vm.yaml", []byte("name: test\nmachine_type: n1-standard-1\n"))
+	_, err = tmpl.Render(nil)
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, gce.TemplateDecode, e.Stage)
+	assert.Equal(t, 2, e.Line)
+	assert.Equal(t, "machine_type: n1-standard-1", e.Context)
+
+	tmpl, _ = gce.ParseVmTemplate("vm.yaml", []byte("name: test\nlabels:\n  Env: prod\n"))
+	_, err = tmpl.Render(nil)
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, gce.TemplateValidate, e.Stage)
+	assert.Contains(t, err.Error(), "machineType is required")
+	assert.Contains(t, err.Error(), "exactly one boot disk is required")
+	assert.Contains(t, err.Error(), "networkInterfaces is required")
+	assert.Contains(t, err.Error(), "invalid label Env=prod")
+}
@@ -0,0 +1,220 @@
+package gce_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/browny/gogoo/gce"
+	"github.com/browny/gogoo/gerror"
+	"github.com/browny/gogoo/gogootest"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+)
+
+func TestVm(t *testing.T) {
+	server, g := gogootest.NewGoGoo()
+	defer server.Close()
+
+	// VM
+	assert.Nil(t, g.NewVm(gogootest.ProjectId, fakeZone, fakeVm("instance-test")))
+	vm, err := g.GetVm(gogootest.ProjectId, fakeZone, "instance-test")
+	assert.Nil(t, err)
+	assert.Equal(t, "RUNNING", vm.Status)
+	natIP, err := g.GetNatIP(vm)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, natIP)
+	networkIP, err := g.GetNetworkIP(vm)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, networkIP)
+
+	_, err = g.GetVm(gogootest.ProjectId, fakeZone, "instance-not-existed")
+	assert.NotNil(t, err)
+
+	vms, err := g.ListVms(gogootest.ProjectId, fakeZone)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(vms.Items))
+
+	// Stop, change machine type and start
+	checker := func(status string) func(projectId, zone, instanceName string) (bool, error) {
+		return func(projectId, zone, instanceName string) (bool, error) {
+			vm, err := g.GetVm(projectId, zone, instanceName)
+			return err == nil && vm.Status == status, err
+		}
+	}
+	_, err = g.StopVm(gogootest.ProjectId, fakeZone, "instance-test", checker("TERMINATED"))
+	assert.Nil(t, err)
+	_, err = g.SetMachineType(gogootest.ProjectId, fakeZone, "instance-test", "f1-micro")
+	assert.Nil(t, err)
+	_, err = g.StartVm(gogootest.ProjectId, fakeZone, "instance-test", checker("RUNNING"))
+	assert.Nil(t, err)
+
+	vm, _ = g.GetVm(gogootest.ProjectId, fakeZone, "instance-test")
+	assert.True(t, strings.HasSuffix(vm.MachineType, "/machineTypes/f1-micro"))
+
+	// Tags
+	g.AttachTags(gogootest.ProjectId, fakeZone, "instance-test", []string{"rtc-8000"})
+	vm, _ = g.GetVm(gogootest.ProjectId, fakeZone, "instance-test")
+	assert.Equal(t, []string{"rtc-8000"}, vm.Tags.Items)
+
+	g.DetachTags(gogootest.ProjectId, fakeZone, "instance-test", []string{"rtc-8000"})
+	vm, _ = g.GetVm(gogootest.ProjectId, fakeZone, "instance-test")
+	assert.Empty(t, vm.Tags.Items)
+
+	// Instance group
+	server.AddInstanceGroup(fakeZone, &compute.InstanceGroup{Name: "group-test"})
+	_, err = g.AddInstancesIntoInstanceGroup(
+		gogootest.ProjectId, fakeZone, "group-test", []string{vm.SelfLink})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{vm.SelfLink}, server.InstanceGroupMembers(fakeZone, "group-test"))
+
+	assert.Nil(t, g.DeleteVm(gogootest.ProjectId, fakeZone, "instance-test"))
+	_, err = g.GetVm(gogootest.ProjectId, fakeZone, "instance-test")
+	assert.NotNil(t, err)
+}
+
+func TestDisksAndSnapshots(t *testing.T) {
+	server, g := gogootest.NewGoGoo()
+	defer server.Close()
+
+	server.AddImage(&compute.Image{Name: "image-test"})
+	server.AddSnapshot(&compute.Snapshot{Name: "snapshot-test"})
+
+	images, err := g.ListImages(gogootest.ProjectId)
+	assert.Nil(t, err)
+	assert.Equal(t, "image-test", images.Items[0].Name)
+
+	snapshots, err := g.GetSnapshots(gogootest.ProjectId)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(snapshots))
+
+	assert.Nil(t, g.NewDisk(gogootest.ProjectId, fakeZone, "disk-test", "global/snapshots/snapshot-test", 20))
+	disk, err := g.GetDisk(gogootest.ProjectId, fakeZone, "disk-test")
+	assert.Nil(t, err)
+	assert.Equal(t, "READY", disk.Status)
+	assert.Equal(t, int64(20), disk.SizeGb)
+	assert.Equal(t, "snapshot-test", g.GetSnapshotOfDisk(disk))
+
+	disks, _ := g.ListDisks(gogootest.ProjectId, fakeZone)
+	assert.Equal(t, 1, len(disks.Items))
+
+	assert.Nil(t, g.DeleteDisk(gogootest.ProjectId, fakeZone, "disk-test"))
+	assert.NotNil(t, g.DeleteDisk(gogootest.ProjectId, fakeZone, "disk-test"))
+}
+
+func TestContext(t *testing.T) {
+	server, g := gogootest.NewGoGoo()
+	defer server.Close()
+
+	server.AddInstance(fakeZone, &compute.Instance{Name: "instance-test", Status: "PROVISIONING"})
+
+	// The polling loop is aborted by the deadline of the context rather than `VmRunningTimeout`
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	observer := make(chan bool)
+	go g.ProbeVmRunningContext(ctx, gogootest.ProjectId, fakeZone, "instance-test", observer)
+	assert.False(t, <-observer)
+	assert.True(t, time.Since(start) < time.Second)
+
+	// The timeout and the poll interval are set per manager
+	manager := g.Compute.(*gce.GceManager)
+	manager.StatusPollInterval = 10 * time.Millisecond
+	manager.Timeouts.VmRunning = 50 * time.Millisecond
+	requests := server.Requests()
+	start = time.Now()
+	go g.ProbeVmRunning(gogootest.ProjectId, fakeZone, "instance-test", observer)
+	assert.False(t, <-observer)
+	assert.True(t, time.Since(start) < time.Second)
+	assert.True(t, server.Requests()-requests > 1)
+
+	// The requests are not sent once the context is cancelled
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+
+	_, err := g.GetVmContext(ctx, gogootest.ProjectId, fakeZone, "instance-test")
+	assert.NotNil(t, err)
+	assert.NotNil(t, g.NewVmContext(ctx, gogootest.ProjectId, fakeZone, fakeVm("instance-cancelled")))
+	_, err = g.GetVm(gogootest.ProjectId, fakeZone, "instance-cancelled")
+	assert.NotNil(t, err)
+	_, err = g.GetAvgCpuUtilizationContext(ctx, gogootest.ProjectId, "instance-test", "10m")
+	assert.NotNil(t, err)
+}
+
+func TestErrors(t *testing.T) {
+	server, g := gogootest.NewGoGoo()
+	defer server.Close()
+
+	_, err := g.GetVm(gogootest.ProjectId, fakeZone, "instance-not-existed")
+	assert.True(t, gerror.IsNotFound(err))
+	var e *gerror.Error
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, "gce", e.Service)
+	assert.Equal(t, "GetVm", e.Operation)
+	assert.Equal(t, http.StatusNotFound, e.StatusCode)
+	var apiErr *googleapi.Error
+	assert.True(t, errors.As(err, &apiErr))
+
+	server.AddDisk(fakeZone, &compute.Disk{Name: "disk-test"})
+	err = g.NewDisk(gogootest.ProjectId, fakeZone, "disk-test", "", 10)
+	assert.True(t, gerror.IsAlreadyExists(err))
+	assert.False(t, gerror.IsNotFound(err))
+
+	_, err = g.GetLatestSnapshot("snapshot", nil)
+	assert.True(t, gerror.IsNotFound(err))
+
+	_, err = g.GetAvgCpuUtilization(gogootest.ProjectId, "instance-not-existed", "10m")
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, "gcm", e.Service)
+}
+
+func TestDelete(t *testing.T) {
+	server, g := gogootest.NewGoGoo()
+	defer server.Close()
+
+	server.SetDeletionPolls(2)
+	for _, name := range []string{"disk-data", "disk-scratch", "disk-other", "disk-lag"} {
+		server.AddDisk(fakeZone, &compute.Disk{Name: name})
+	}
+	vm := fakeVm("instance-test")
+	vm.Disks = append(vm.Disks,
+		&compute.AttachedDisk{Source: fmt.Sprintf("zones/%s/disks/disk-data", fakeZone)},
+		&compute.AttachedDisk{Source: fmt.Sprintf("zones/%s/disks/disk-scratch", fakeZone), AutoDelete: true},
+	)
+	server.AddInstance(fakeZone, vm)
+
+	// The attached disk can not be deleted
+	err := g.DeleteDisk(gogootest.ProjectId, fakeZone, "disk-data")
+	assert.NotNil(t, err)
+
+	assert.Nil(t, g.DeleteVm(gogootest.ProjectId, fakeZone, "instance-test", gce.WithAttachedDisks()))
+	_, err = g.GetVm(gogootest.ProjectId, fakeZone, "instance-test")
+	assert.True(t, gerror.IsNotFound(err))
+	for _, name := range []string{"instance-test", "disk-data", "disk-scratch"} {
+		_, err = g.GetDisk(gogootest.ProjectId, fakeZone, name)
+		assert.True(t, gerror.IsNotFound(err), name)
+	}
+
+	assert.Nil(t, g.DeleteDisk(gogootest.ProjectId, fakeZone, "disk-other", gce.WaitUntilDeleted(0)))
+	_, err = g.GetDisk(gogootest.ProjectId, fakeZone, "disk-other")
+	assert.True(t, gerror.IsNotFound(err))
+
+	// Without waiting, the disk is still being deleted
+	assert.Nil(t, g.DeleteDisk(gogootest.ProjectId, fakeZone, "disk-lag"))
+	disk, err := g.GetDisk(gogootest.ProjectId, fakeZone, "disk-lag")
+	assert.Nil(t, err)
+	assert.Equal(t, "DELETING", disk.Status)
+
+	server.SetDeletionPolls(1000)
+	server.AddInstance(fakeZone, fakeVm("instance-slow"))
+	err = g.DeleteVm(gogootest.ProjectId, fakeZone, "instance-slow", gce.WaitUntilDeleted(50*time.Millisecond))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.False(t, gerror.IsNotFound(err))
+}
@@ -0,0 +1,322 @@
+package gce
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/browny/gogoo/gerror"
+
+	log "github.com/cihub/seelog"
+	"golang.org/x/net/context"
+)
+
+// Kinds of the resources watched by Watcher
+const (
+	KindInstance = "instance"
+	KindDisk     = "disk"
+	KindSnapshot = "snapshot"
+)
+
+// StatusNotFound is the status of a watched resource which does not exist
+const StatusNotFound = "NOT_FOUND"
+
+// WatcherBuffer is the buffer size of the channel of each subscriber
+const WatcherBuffer = 64
+
+// StateEvent is a transition of the status of a watched resource, e.g. RUNNING to STOPPING.
+// The first event of a resource has empty From. The statuses between two polls are not observed,
+// e.g. PROVISIONING to RUNNING is reported if STAGING lasts shorter than the poll interval.
+type StateEvent struct {
+	Kind string
+	Zone string
+	Name string
+	From string
+	To   string
+	// Err is the failure to get the resource, the status is not changed
+	Err  error
+	Time time.Time
+}
+
+func (e StateEvent) String() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s %s/%s: %v", e.Kind, e.Zone, e.Name, e.Err)
+	}
+
+	return fmt.Sprintf("%s %s/%s: %s -> %s", e.Kind, e.Zone, e.Name, e.From, e.To)
+}
+
+type watchTarget struct {
+	kind string
+	zone string
+	name string
+}
+
+//go:generate mockery -name=Watcher
+
+// Watcher polls the statuses of a set of instances, disks and snapshots, and publishes their transitions
+// to all subscribers.
+//
+//	watcher := manager.NewWatcher(projectId, gce.WithPollInterval(5*time.Second))
+//	watcher.WatchVm(zone, vmName)
+//	events := watcher.Subscribe()
+//	go watcher.Run(ctx)
+//	for event := range events {
+//		...
+//	}
+type Watcher interface {
+	// WatchVm adds the VM to watch, it can be called while the watcher is running
+	WatchVm(zone, vmName string)
+	// WatchDisk adds the disk to watch, it can be called while the watcher is running
+	WatchDisk(zone, diskName string)
+	// WatchSnapshot adds the snapshot to watch, the zone of its events is empty as snapshots are global
+	WatchSnapshot(snapshotName string)
+	// Unwatch stops watching the resource of the kind
+	Unwatch(kind, zone, name string)
+	// Subscribe returns the channel receiving all events from now on, it is closed once Run returns.
+	// A slow subscriber blocks the watcher rather than missing events.
+	Subscribe() <-chan StateEvent
+	// Run polls the resources till ctx is done or the timeout of watcher is reached,
+	// and returns the error of ctx. The channels of subscribers are closed then.
+	Run(ctx context.Context) error
+}
+
+type watcher struct {
+	manager   *GceManager
+	projectId string
+	interval  time.Duration
+	timeout   time.Duration
+
+	mu          sync.Mutex
+	targets     map[watchTarget]string
+	subscribers []chan StateEvent
+	done        bool
+}
+
+var _ Watcher = (*watcher)(nil)
+
+// WatcherOption configures Watcher
+type WatcherOption func(*watcher)
+
+// WithPollInterval sets the interval to poll the resources, `StatusPollInterval` of
+// the manager is used by default
+func WithPollInterval(interval time.Duration) WatcherOption {
+	return func(w *watcher) {
+		w.interval = interval
+	}
+}
+
+// WithWatchTimeout makes Run return after timeout, there is no timeout by default
+func WithWatchTimeout(timeout time.Duration) WatcherOption {
+	return func(w *watcher) {
+		w.timeout = timeout
+	}
+}
+
+// NewWatcher creates a watcher of the resources in the project
+func (manager *GceManager) NewWatcher(projectId string, opts ...WatcherOption) Watcher {
+	return manager.newWatcher(projectId, opts...)
+}
+
+func (manager *GceManager) newWatcher(projectId string, opts ...WatcherOption) *watcher {
+	w := &watcher{
+		manager:   manager,
+		projectId: projectId,
+		interval:  orDefault(manager.StatusPollInterval, StatusPollInterval),
+		targets:   map[watchTarget]string{},
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// WatchVm adds the VM to watch, it can be called while the watcher is running
+func (w *watcher) WatchVm(zone, vmName string) {
+	w.watch(watchTarget{kind: KindInstance, zone: zone, name: vmName})
+}
+
+// WatchDisk adds the disk to watch, it can be called while the watcher is running
+func (w *watcher) WatchDisk(zone, diskName string) {
+	w.watch(watchTarget{kind: KindDisk, zone: zone, name: diskName})
+}
+
+// WatchSnapshot adds the snapshot to watch, the zone of its events is empty as snapshots are global
+func (w *watcher) WatchSnapshot(snapshotName string) {
+	w.watch(watchTarget{kind: KindSnapshot, name: snapshotName})
+}
+
+// Unwatch stops watching the resource of the kind
+func (w *watcher) Unwatch(kind, zone, name string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delete(w.targets, watchTarget{kind: kind, zone: zone, name: name})
+}
+
+func (w *watcher) watch(target watchTarget) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.targets[target]; !ok {
+		w.targets[target] = ""
+	}
+}
+
+// Subscribe returns the channel receiving all events from now on, it is closed once Run returns.
+// A slow subscriber blocks the watcher rather than missing events.
+func (w *watcher) Subscribe() <-chan StateEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	events := make(chan StateEvent, WatcherBuffer)
+	if w.done {
+		close(events)
+		return events
+	}
+	w.subscribers = append(w.subscribers, events)
+
+	return events
+}
+
+// Run polls the resources till ctx is done or the timeout of watcher is reached,
+// and returns the error of ctx. The channels of subscribers are closed then.
+func (w *watcher) Run(ctx context.Context) error {
+	if w.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.timeout)
+		defer cancel()
+	}
+	defer w.close()
+
+	for {
+		w.pollAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(w.interval):
+		}
+	}
+}
+
+func (w *watcher) pollAll(ctx context.Context) {
+	w.mu.Lock()
+	targets := make([]watchTarget, 0, len(w.targets))
+	for target := range w.targets {
+		targets = append(targets, target)
+	}
+	w.mu.Unlock()
+
+	for _, target := range targets {
+		status, err := w.status(ctx, target)
+		if ctx.Err() != nil {
+			return
+		}
+
+		w.mu.Lock()
+		last, watching := w.targets[target]
+		if watching && err == nil {
+			w.targets[target] = status
+		}
+		w.mu.Unlock()
+		if !watching || (err == nil && status == last) {
+			continue
+		}
+
+		event := StateEvent{Kind: target.kind, Zone: target.zone, Name: target.name, Time: time.Now()}
+		if err != nil {
+			event.From, event.To, event.Err = last, last, err
+		} else {
+			event.From, event.To = last, status
+		}
+		log.Tracef("Watch: %s", event)
+		w.publish(ctx, event)
+	}
+}
+
+// status gets the status of the resource, StatusNotFound if it does not exist
+func (w *watcher) status(ctx context.Context, target watchTarget) (string, error) {
+	var status string
+	var err error
+	switch target.kind {
+	case KindInstance:
+		vm, getErr := w.manager.GetVmContext(ctx, w.projectId, target.zone, target.name)
+		if err = getErr; err == nil {
+			status = vm.Status
+		}
+	case KindDisk:
+		disk, getErr := w.manager.GetDiskContext(ctx, w.projectId, target.zone, target.name)
+		if err = getErr; err == nil {
+			status = disk.Status
+		}
+	case KindSnapshot:
+		snapshot, getErr := w.manager.GetSnapshotContext(ctx, w.projectId, target.name)
+		if err = getErr; err == nil {
+			status = snapshot.Status
+		}
+	default:
+		return "", fmt.Errorf("unknown kind[%s]", target.kind)
+	}
+	if gerror.IsNotFound(err) {
+		return StatusNotFound, nil
+	}
+
+	return status, err
+}
+
+func (w *watcher) publish(ctx context.Context, event StateEvent) {
+	w.mu.Lock()
+	subscribers := append([]chan StateEvent{}, w.subscribers...)
+	w.mu.Unlock()
+
+	for _, events := range subscribers {
+		select {
+		case events <- event:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (w *watcher) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, events := range w.subscribers {
+		close(events)
+	}
+	w.subscribers = nil
+	w.done = true
+}
+
+// waitStatus watches the resource till it is in the status, the error is not wrapped
+func (manager *GceManager) waitStatus(
+	ctx context.Context, projectId, kind, zone, name, status string, timeout time.Duration) error {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w := manager.newWatcher(projectId, WithWatchTimeout(timeout))
+	w.watch(watchTarget{kind: kind, zone: zone, name: name})
+	events := w.Subscribe()
+
+	result := make(chan error, 1)
+	go func() {
+		result <- w.Run(ctx)
+	}()
+
+	last := ""
+	for event := range events {
+		if event.Err != nil {
+			log.Tracef("Wait status: %s", event)
+			continue
+		}
+		if last = event.To; last == status {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s[%s] is %s, not %s: %w", kind, name, last, status, <-result)
+}
@@ -0,0 +1,71 @@
+package gce_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/browny/gogoo/gce"
+	"github.com/browny/gogoo/gogootest"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	compute "google.golang.org/api/compute/v1"
+)
+
+func TestWatcher(t *testing.T) {
+	server, g := gogootest.NewGoGoo()
+	defer server.Close()
+
+	server.SetStatusTransitions(true)
+	server.AddInstance(fakeZone, fakeVm("instance-test"))
+
+	watcher := g.NewWatcher(gogootest.ProjectId,
+		gce.WithPollInterval(5*time.Millisecond), gce.WithWatchTimeout(5*time.Second))
+	watcher.WatchVm(fakeZone, "instance-test")
+	watcher.WatchDisk(fakeZone, "disk-test")
+	events, others := watcher.Subscribe(), watcher.Subscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		result <- watcher.Run(ctx)
+	}()
+
+	transitions := map[string][]string{}
+	received := []gce.StateEvent{}
+	for event := range events {
+		assert.Nil(t, event.Err)
+		received = append(received, event)
+		transitions[event.Name] = append(transitions[event.Name], event.From+"->"+event.To)
+
+		switch {
+		case event.Name == "disk-test" && event.To == gce.StatusNotFound:
+			server.AddDisk(fakeZone, &compute.Disk{Name: "disk-test"})
+		case event.Name == "instance-test" && event.To == "RUNNING":
+			_, err := g.StopVm(gogootest.ProjectId, fakeZone, "instance-test", nil)
+			assert.Nil(t, err)
+		}
+
+		if len(transitions["instance-test"]) == 5 && len(transitions["disk-test"]) == 3 {
+			cancel()
+		}
+	}
+	assert.Equal(t, context.Canceled, <-result)
+
+	assert.Equal(t, []string{
+		"->PROVISIONING", "PROVISIONING->STAGING", "STAGING->RUNNING", "RUNNING->STOPPING", "STOPPING->TERMINATED",
+	}, transitions["instance-test"])
+	assert.Equal(t, []string{"->NOT_FOUND", "NOT_FOUND->CREATING", "CREATING->READY"}, transitions["disk-test"])
+
+	// All subscribers receive the same events, and their channels are closed
+	for _, event := range received {
+		assert.Equal(t, event, <-others)
+	}
+	_, open := <-others
+	assert.False(t, open)
+
+	// The watch timeout
+	watcher = g.NewWatcher(gogootest.ProjectId, gce.WithWatchTimeout(10*time.Millisecond))
+	watcher.WatchVm(fakeZone, "instance-test")
+	assert.Equal(t, context.DeadlineExceeded, watcher.Run(context.Background()))
+}
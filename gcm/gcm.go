@@ -3,27 +3,36 @@ package gcm
 
 import (
 	"fmt"
+	"net/http"
 	"time"
 
+	"github.com/browny/gogoo/auth"
+	"github.com/browny/gogoo/gerror"
+
+	"golang.org/x/net/context"
 	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
-	"golang.org/x/oauth2/jwt"
 	monitor "google.golang.org/api/cloudmonitoring/v2beta2"
 )
 
+// Scopes lists the oauth2 scopes required by GcmManager
+var Scopes = []string{
+	monitor.MonitoringScope,
+	monitor.CloudPlatformScope,
+}
+
 // BuildCloudMonitorService builds the singlton service for CloudMonitor
 func BuildCloudMonitorService(serviceEmail string, key []byte) (*monitor.Service, error) {
-	conf := &jwt.Config{
-		Email:      serviceEmail,
-		PrivateKey: key,
-		Scopes: []string{
-			monitor.MonitoringScope,
-			monitor.CloudPlatformScope,
-		},
-		TokenURL: google.JWTTokenURL,
+	ts, err := auth.PemKey(serviceEmail, key).TokenSource(oauth2.NoContext, Scopes...)
+	if err != nil {
+		return nil, err
 	}
 
-	service, err := monitor.New(conf.Client(oauth2.NoContext))
+	return BuildCloudMonitorServiceWithClient(oauth2.NewClient(oauth2.NoContext, ts))
+}
+
+// BuildCloudMonitorServiceWithClient builds the service for CloudMonitor upon an authorized http client
+func BuildCloudMonitorServiceWithClient(client *http.Client) (*monitor.Service, error) {
+	service, err := monitor.New(client)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +40,16 @@ func BuildCloudMonitorService(serviceEmail string, key []byte) (*monitor.Service
 	return service, nil
 }
 
+//go:generate mockery -name=Monitor
+
+// Monitor is the method set of GcmManager, it is for consumers to mock GcmManager.
+type Monitor interface {
+	GetAvgCpuUtilization(projectId, instanceName, interval string) (float64, error)
+	GetAvgCpuUtilizationContext(ctx context.Context, projectId, instanceName, interval string) (float64, error)
+}
+
+var _ Monitor = (*GcmManager)(nil)
+
 // GcmManager is for low level communication with Google CloudMonitor.
 type GcmManager struct {
 	*monitor.Service `inject:""`
@@ -38,6 +57,13 @@ type GcmManager struct {
 
 // https://cloud.google.com/monitoring/v2beta2/timeseries/list
 func (manager *GcmManager) GetAvgCpuUtilization(projectId, instanceName, interval string) (float64, error) {
+	return manager.GetAvgCpuUtilizationContext(context.Background(), projectId, instanceName, interval)
+}
+
+// GetAvgCpuUtilizationContext is GetAvgCpuUtilization with the context of the request
+func (manager *GcmManager) GetAvgCpuUtilizationContext(
+	ctx context.Context, projectId, instanceName, interval string) (float64, error) {
+
 	label := fmt.Sprintf("compute.googleapis.com/instance_name==%s", instanceName)
 	metric := "compute.googleapis.com/instance/cpu/utilization"
 
@@ -47,14 +73,15 @@ func (manager *GcmManager) GetAvgCpuUtilization(projectId, instanceName, interva
 		Timespan(interval).
 		Window(interval).
 		Aggregator("mean").
+		Context(ctx).
 		Do()
 
 	if err != nil {
-		return 0, err
+		return 0, gerror.New("gcm", "GetAvgCpuUtilization", err)
 	}
 
 	if len(response.Timeseries) == 0 {
-		return 0, fmt.Errorf("No data")
+		return 0, gerror.Errorf("gcm", "GetAvgCpuUtilization", "No data: instance[%s]", instanceName)
 	}
 
 	utilization := *response.Timeseries[0].Points[0].DoubleValue
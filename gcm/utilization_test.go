@@ -0,0 +1,23 @@
+package gcm_test
+
+import (
+	"testing"
+
+	"github.com/browny/gogoo/gogootest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAvgCpuUtilization(t *testing.T) {
+	server, g := gogootest.NewGoGoo()
+	defer server.Close()
+
+	server.SetCpuUtilization("instance-test", 0.42)
+
+	utilization, err := g.GetAvgCpuUtilization(gogootest.ProjectId, "instance-test", "10m")
+	assert.Nil(t, err)
+	assert.Equal(t, 0.42, utilization)
+
+	_, err = g.GetAvgCpuUtilization(gogootest.ProjectId, "instance-not-existed", "10m")
+	assert.NotNil(t, err)
+}
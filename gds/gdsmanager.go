@@ -2,41 +2,123 @@
 package gds
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 	"reflect"
 
+	"github.com/browny/gogoo/auth"
+	"github.com/browny/gogoo/gerror"
+
 	log "github.com/cihub/seelog"
 	"golang.org/x/net/context"
-	"golang.org/x/oauth2/google"
-	"golang.org/x/oauth2/jwt"
+	"golang.org/x/oauth2"
 	"google.golang.org/cloud"
 	"google.golang.org/cloud/datastore"
 )
 
-var gdsError = fmt.Errorf("GDS Error")
+// gdsError wraps the cause into *gerror.Error, datastore.ErrNoSuchEntity is classified as not found
+func gdsError(operation string, err error) error {
+	result := gerror.New("gds", operation, err)
+	if e, ok := result.(*gerror.Error); ok && noSuchEntity(err) {
+		e.StatusCode = http.StatusNotFound
+		e.Reason = gerror.ReasonNotFound
+	}
+
+	return result
+}
+
+// noSuchEntity reports whether the error is datastore.ErrNoSuchEntity, or a datastore.MultiError
+// of GetMulti whose failures are all datastore.ErrNoSuchEntity
+func noSuchEntity(err error) bool {
+	var multiErr datastore.MultiError
+	if !errors.As(err, &multiErr) {
+		return errors.Is(err, datastore.ErrNoSuchEntity)
+	}
+
+	found := false
+	for _, e := range multiErr {
+		if e == nil {
+			continue
+		}
+		if !errors.Is(e, datastore.ErrNoSuchEntity) {
+			return false
+		}
+		found = true
+	}
+
+	return found
+}
+
+// BasePath is the default endpoint of datastore api
+const BasePath = "https://www.googleapis.com/datastore/v1beta2/datasets/"
+
+// Scopes lists the oauth2 scopes required by GdsManager
+var Scopes = []string{
+	datastore.ScopeDatastore,
+	datastore.ScopeUserEmail,
+}
 
 // BuildGdsContext builds the singlton context for GdsManager
 func BuildGdsContext(serviceEmail string, key []byte, projectId string) (context.Context, *datastore.Client, error) {
-	conf := &jwt.Config{
-		Email:      serviceEmail,
-		PrivateKey: key,
-		Scopes: []string{
-			datastore.ScopeDatastore,
-			datastore.ScopeUserEmail,
-		},
-		TokenURL: google.JWTTokenURL,
+	ctx := context.Background()
+	ts, err := auth.PemKey(serviceEmail, key).TokenSource(ctx, Scopes...)
+	if err != nil {
+		return ctx, nil, err
 	}
 
+	return BuildGdsContextWithClient(oauth2.NewClient(ctx, ts), projectId)
+}
+
+// BuildGdsContextWithClient builds the context for GdsManager upon an authorized http client.
+// Extra client options are appended to the default ones.
+func BuildGdsContextWithClient(
+	client *http.Client, projectId string, opts ...cloud.ClientOption) (
+	context.Context, *datastore.Client, error) {
+
 	ctx := context.Background()
-	client, err := datastore.NewClient(ctx, projectId, cloud.WithTokenSource(conf.TokenSource(ctx)))
+	opts = append([]cloud.ClientOption{cloud.WithBaseHTTP(client)}, opts...)
+	datastoreClient, err := datastore.NewClient(ctx, projectId, opts...)
 	if err != nil {
 		return ctx, nil, err
 	}
 
-	return ctx, client, nil
+	return ctx, datastoreClient, nil
 }
 
-// GdsManager is for low level communication with Google datastore
+//go:generate mockery -name=Store
+
+// Store is the method set of GdsManager, it is for consumers to mock GdsManager.
+type Store interface {
+	Setup(suffixOfKind string)
+	BuildKey(kind, keyName string) *datastore.Key
+	Put(key *datastore.Key, entity interface{}) (*datastore.Key, error)
+	PutContext(ctx context.Context, key *datastore.Key, entity interface{}) (*datastore.Key, error)
+	PutUnique(key *datastore.Key, entity interface{}) error
+	PutUniqueContext(ctx context.Context, key *datastore.Key, entity interface{}) error
+	Get(key *datastore.Key, entity interface{}) error
+	GetContext(ctx context.Context, key *datastore.Key, entity interface{}) error
+	GetMulti(keys []*datastore.Key, dst interface{}) error
+	GetMultiContext(ctx context.Context, keys []*datastore.Key, dst interface{}) error
+	GetKeysOnly(query *datastore.Query) ([]*datastore.Key, error)
+	GetKeysOnlyContext(ctx context.Context, query *datastore.Query) ([]*datastore.Key, error)
+	Delete(key *datastore.Key) error
+	DeleteContext(ctx context.Context, key *datastore.Key) error
+	GetAll(query *datastore.Query, result interface{}) ([]*datastore.Key, error)
+	GetAllContext(ctx context.Context, query *datastore.Query, result interface{}) ([]*datastore.Key, error)
+	GetCount(query *datastore.Query) (int, error)
+	GetCountContext(ctx context.Context, query *datastore.Query) (int, error)
+	DeleteAll(kindName string) error
+	DeleteAllContext(ctx context.Context, kindName string) error
+	GetTx() *datastore.Transaction
+	GetTxContext(ctx context.Context) *datastore.Transaction
+}
+
+var _ Store = (*GdsManager)(nil)
+
+// GdsManager is for low level communication with Google datastore.
+// The methods without ctx run with context.Background(), use the `Context` variants
+// to carry the deadline and cancellation of the caller.
 type GdsManager struct {
 	SuffixOfKind string
 
@@ -53,11 +135,16 @@ func (manager *GdsManager) BuildKey(kind, keyName string) *datastore.Key {
 
 // Put inserts/updates the entity
 func (manager *GdsManager) Put(key *datastore.Key, entity interface{}) (*datastore.Key, error) {
+	return manager.PutContext(context.Background(), key, entity)
+}
+
+// PutContext is Put with the context of the request
+func (manager *GdsManager) PutContext(ctx context.Context, key *datastore.Key, entity interface{}) (*datastore.Key, error) {
 	log.Tracef("Put entity: key[%s]", key.Name())
 
 	var resultKey *datastore.Key
-	if key, err := manager.Client.Put(context.Background(), key, entity); err != nil {
-		return nil, err
+	if key, err := manager.Client.Put(ctx, key, entity); err != nil {
+		return nil, gdsError("Put", err)
 	} else {
 		resultKey = key
 	}
@@ -73,22 +160,37 @@ func (manager *GdsManager) Put(key *datastore.Key, entity interface{}) (*datasto
 
 // PutUnique inserts/updates entity with unique key (if the same key existed, issue error)
 func (manager *GdsManager) PutUnique(key *datastore.Key, entity interface{}) error {
+	return manager.PutUniqueContext(context.Background(), key, entity)
+}
+
+// PutUniqueContext is PutUnique with the context of the transaction
+func (manager *GdsManager) PutUniqueContext(ctx context.Context, key *datastore.Key, entity interface{}) error {
 	log.Tracef("PutUnique entity: key[%s]", key.Name())
 
-	tx := manager.GetTx()
+	tx, err := manager.Client.NewTransaction(ctx, datastore.Serializable)
+	if err != nil {
+		return gdsError("PutUnique", err)
+	}
 
 	if err := tx.Get(key, entity); err == nil {
-		return fmt.Errorf("Unique condition violation")
+		tx.Rollback()
+		return &gerror.Error{
+			Service:    "gds",
+			Operation:  "PutUnique",
+			StatusCode: http.StatusConflict,
+			Reason:     gerror.ReasonAlreadyExists,
+			Err:        fmt.Errorf("Unique condition violation: key[%s]", key.Name()),
+		}
 	}
 
-	_, err := tx.Put(key, entity)
-	if err != nil {
-		return err
+	if _, err := tx.Put(key, entity); err != nil {
+		tx.Rollback()
+		return gdsError("PutUnique", err)
 	}
 
 	if _, err := tx.Commit(); err != nil {
 		log.Warnf("%s", err.Error())
-		return err
+		return gdsError("PutUnique", err)
 	}
 
 	return nil
@@ -96,13 +198,18 @@ func (manager *GdsManager) PutUnique(key *datastore.Key, entity interface{}) err
 
 // Get gets the entity by key
 func (manager *GdsManager) Get(key *datastore.Key, entity interface{}) error {
+	return manager.GetContext(context.Background(), key, entity)
+}
+
+// GetContext is Get with the context of the request
+func (manager *GdsManager) GetContext(ctx context.Context, key *datastore.Key, entity interface{}) error {
 	log.Tracef("Get entity: key[%s]", key.Name())
 
-	err := manager.Client.Get(context.Background(), key, entity)
+	err := manager.Client.Get(ctx, key, entity)
 	if err != nil {
 		log.Tracef("Error: %s: kind[%s], key[%s]", err.Error(), key.Kind(), key.Name())
 
-		return gdsError
+		return gdsError("Get", err)
 	}
 
 	// Use reflection to setup key of entity
@@ -116,11 +223,16 @@ func (manager *GdsManager) Get(key *datastore.Key, entity interface{}) error {
 
 // GetMulti gets the entities by keys
 func (manager *GdsManager) GetMulti(keys []*datastore.Key, dst interface{}) error {
-	err := manager.Client.GetMulti(context.Background(), keys, dst)
+	return manager.GetMultiContext(context.Background(), keys, dst)
+}
+
+// GetMultiContext is GetMulti with the context of the request
+func (manager *GdsManager) GetMultiContext(ctx context.Context, keys []*datastore.Key, dst interface{}) error {
+	err := manager.Client.GetMulti(ctx, keys, dst)
 	if err != nil {
 		log.Tracef("Error: %s", err.Error())
 
-		return gdsError
+		return gdsError("GetMulti", err)
 	}
 
 	return nil
@@ -128,11 +240,16 @@ func (manager *GdsManager) GetMulti(keys []*datastore.Key, dst interface{}) erro
 
 // GetKeysOnly gets only keys bu query
 func (manager *GdsManager) GetKeysOnly(query *datastore.Query) ([]*datastore.Key, error) {
+	return manager.GetKeysOnlyContext(context.Background(), query)
+}
+
+// GetKeysOnlyContext is GetKeysOnly with the context of the request
+func (manager *GdsManager) GetKeysOnlyContext(ctx context.Context, query *datastore.Query) ([]*datastore.Key, error) {
 	query = query.KeysOnly()
 
 	type Any struct{}
 	result := &[]Any{}
-	if keys, err := manager.GetAll(query, result); err != nil {
+	if keys, err := manager.GetAllContext(ctx, query, result); err != nil {
 		return nil, err
 	} else {
 		return keys, nil
@@ -141,17 +258,22 @@ func (manager *GdsManager) GetKeysOnly(query *datastore.Query) ([]*datastore.Key
 
 // Delete deletes the entity by key (if the entity is not existed, there is no error)
 func (manager *GdsManager) Delete(key *datastore.Key) error {
+	return manager.DeleteContext(context.Background(), key)
+}
+
+// DeleteContext is Delete with the context of the request
+func (manager *GdsManager) DeleteContext(ctx context.Context, key *datastore.Key) error {
 	if key == nil {
-		return fmt.Errorf("key is nil")
+		return gerror.Errorf("gds", "Delete", "key is nil")
 	}
 
 	log.Tracef("Delete entity: key[%s]", key.Name())
 
-	err := manager.Client.Delete(context.Background(), key)
+	err := manager.Client.Delete(ctx, key)
 	if err != nil {
 		log.Tracef("Error: %s", err.Error())
 
-		return gdsError
+		return gdsError("Delete", err)
 	}
 
 	return nil
@@ -159,13 +281,20 @@ func (manager *GdsManager) Delete(key *datastore.Key) error {
 
 // GetAll fetchs all entities by the query. The parameter `result` should be type of `*[]*<Entity>`
 func (manager *GdsManager) GetAll(query *datastore.Query, result interface{}) ([]*datastore.Key, error) {
+	return manager.GetAllContext(context.Background(), query, result)
+}
+
+// GetAllContext is GetAll with the context of the request
+func (manager *GdsManager) GetAllContext(
+	ctx context.Context, query *datastore.Query, result interface{}) ([]*datastore.Key, error) {
+
 	log.Trace("Get all by query")
 
-	keys, err := manager.Client.GetAll(context.Background(), query, result)
+	keys, err := manager.Client.GetAll(ctx, query, result)
 	if err != nil {
 		log.Warnf("Error: %s", err.Error())
 
-		return nil, gdsError
+		return nil, gdsError("GetAll", err)
 	}
 
 	// Use reflection to setup keys of entities
@@ -182,13 +311,18 @@ func (manager *GdsManager) GetAll(query *datastore.Query, result interface{}) ([
 
 // GetCount return count of result
 func (manager *GdsManager) GetCount(query *datastore.Query) (int, error) {
+	return manager.GetCountContext(context.Background(), query)
+}
+
+// GetCountContext is GetCount with the context of the request
+func (manager *GdsManager) GetCountContext(ctx context.Context, query *datastore.Query) (int, error) {
 	log.Trace("Get count by query")
 
-	count, err := manager.Client.Count(context.Background(), query)
+	count, err := manager.Client.Count(ctx, query)
 	if err != nil {
 		log.Warnf("Error: %s", err.Error())
 
-		return 0, gdsError
+		return 0, gdsError("GetCount", err)
 	}
 
 	return count, nil
@@ -196,6 +330,11 @@ func (manager *GdsManager) GetCount(query *datastore.Query) (int, error) {
 
 // DeleteAll deletes all entities under some Kind
 func (manager *GdsManager) DeleteAll(kindName string) error {
+	return manager.DeleteAllContext(context.Background(), kindName)
+}
+
+// DeleteAllContext is DeleteAll with the context of the requests, it stops once ctx is done
+func (manager *GdsManager) DeleteAllContext(ctx context.Context, kindName string) error {
 	log.Trace("Delete all")
 
 	query := datastore.NewQuery(kindName).KeysOnly()
@@ -203,15 +342,15 @@ func (manager *GdsManager) DeleteAll(kindName string) error {
 	type Any struct{}
 	result := &[]Any{}
 
-	keys, err := manager.GetAll(query, result)
+	keys, err := manager.GetAllContext(ctx, query, result)
 	if err != nil {
-		return gdsError
+		return err
 	}
 
 	for _, key := range keys {
-		err = manager.Delete(key)
+		err = manager.DeleteContext(ctx, key)
 		if err != nil {
-			return gdsError
+			return err
 		}
 	}
 
@@ -220,6 +359,11 @@ func (manager *GdsManager) DeleteAll(kindName string) error {
 
 // GetTx gets the datastore transaction
 func (manager *GdsManager) GetTx() *datastore.Transaction {
-	tx, _ := manager.Client.NewTransaction(context.Background(), datastore.Serializable)
+	return manager.GetTxContext(context.Background())
+}
+
+// GetTxContext gets the datastore transaction bound to ctx
+func (manager *GdsManager) GetTxContext(ctx context.Context) *datastore.Transaction {
+	tx, _ := manager.Client.NewTransaction(ctx, datastore.Serializable)
 	return tx
 }
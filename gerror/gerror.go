@@ -0,0 +1,179 @@
+// Package gerror defines the error returned by all managers of gogoo.
+//
+// The error tells which service and operation fail, the http status and the reason
+// reported by google apis, and wraps the cause, so it can be inspected by errors.Is and errors.As
+//
+//	if gerror.IsNotFound(err) { ... }
+//	if errors.Is(err, gerror.ErrQuotaExceeded) { ... }
+//	var apiErr *googleapi.Error
+//	if errors.As(err, &apiErr) { ... }
+package gerror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"google.golang.org/api/googleapi"
+)
+
+// Sentinels to match the kind of *Error by errors.Is
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrAlreadyExists      = errors.New("already exists")
+	ErrQuotaExceeded      = errors.New("quota exceeded")
+	ErrPreconditionFailed = errors.New("precondition failed")
+	ErrRetryable          = errors.New("retryable")
+)
+
+// Reasons of google apis and compute engine operations used to classify the errors
+const (
+	ReasonNotFound           = "notFound"
+	ReasonAlreadyExists      = "alreadyExists"
+	ReasonConditionNotMet    = "conditionNotMet"
+	ReasonRateLimitExceeded  = "rateLimitExceeded"
+	ReasonQuotaExceeded      = "quotaExceeded"
+	ReasonBackendError       = "backendError"
+	ReasonInternalError      = "internalError"
+	ReasonResourceNotFound   = "RESOURCE_NOT_FOUND"
+	ReasonResourceExists     = "RESOURCE_ALREADY_EXISTS"
+	ReasonOperationQuota     = "QUOTA_EXCEEDED"
+	ReasonUserRateLimit      = "userRateLimitExceeded"
+	ReasonDailyLimitExceeded = "dailyLimitExceeded"
+)
+
+// Error is the error of an operation on some google service
+type Error struct {
+	// Service is the component failed, e.g. "gce", "gds"
+	Service string
+	// Operation is the failed method of the manager, e.g. "NewVm"
+	Operation string
+	// StatusCode is the http status responded by the api, 0 if the request is not responded
+	StatusCode int
+	// Reason is the reason of google api error, e.g. "notFound", or the code of the
+	// failed compute engine operation, e.g. "QUOTA_EXCEEDED"
+	Reason string
+	// Err is the cause
+	Err error
+}
+
+// New wraps the cause into *Error, the http status and reason are taken from the
+// *googleapi.Error in the chain of the cause. It returns nil if err is nil,
+// and returns err as is if it is already *Error.
+func New(service, operation string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*Error); ok {
+		return err
+	}
+
+	e := &Error{Service: service, Operation: operation, Err: err}
+
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		e.StatusCode = apiErr.Code
+		if len(apiErr.Errors) > 0 {
+			e.Reason = apiErr.Errors[0].Reason
+		}
+	}
+
+	return e
+}
+
+// Errorf wraps the formatted message into *Error
+func Errorf(service, operation, format string, a ...interface{}) error {
+	return &Error{Service: service, Operation: operation, Err: fmt.Errorf(format, a...)}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s operation fails: %s: %v", strings.ToUpper(e.Service), e.Operation, e.Err)
+}
+
+// Unwrap returns the cause
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error is the kind of the sentinel, e.g. ErrNotFound
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound ||
+			e.Reason == ReasonNotFound || e.Reason == ReasonResourceNotFound
+	case ErrAlreadyExists:
+		return e.StatusCode == http.StatusConflict ||
+			e.Reason == ReasonAlreadyExists || e.Reason == ReasonResourceExists
+	case ErrQuotaExceeded:
+		return e.Reason == ReasonQuotaExceeded || e.Reason == ReasonOperationQuota ||
+			e.Reason == ReasonDailyLimitExceeded
+	case ErrPreconditionFailed:
+		return e.StatusCode == http.StatusPreconditionFailed || e.Reason == ReasonConditionNotMet
+	case ErrRetryable:
+		return isRetryableStatus(e.StatusCode) ||
+			e.Reason == ReasonRateLimitExceeded || e.Reason == ReasonUserRateLimit ||
+			e.Reason == ReasonBackendError || e.Reason == ReasonInternalError
+	}
+
+	return false
+}
+
+// IsNotFound reports whether the resource does not exist
+func IsNotFound(err error) bool {
+	return is(err, ErrNotFound)
+}
+
+// IsAlreadyExists reports whether the resource to create exists
+func IsAlreadyExists(err error) bool {
+	return is(err, ErrAlreadyExists)
+}
+
+// IsQuotaExceeded reports whether the quota of the project is exceeded
+func IsQuotaExceeded(err error) bool {
+	return is(err, ErrQuotaExceeded)
+}
+
+// IsPreconditionFailed reports whether the fingerprint or other precondition does not match
+func IsPreconditionFailed(err error) bool {
+	return is(err, ErrPreconditionFailed)
+}
+
+// IsRetryable reports whether the failure is transient, i.e. rate limited, 5xx responses,
+// or network timeouts. Cancellation and deadline of the context are not retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if is(err, ErrRetryable) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// is matches the sentinel against *Error, or *googleapi.Error not wrapped by gogoo
+func is(err error, target error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, target) {
+		return true
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		return false
+	}
+
+	return errors.Is(New("", "", err), target)
+}
+
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusInternalServerError ||
+		status == http.StatusBadGateway || status == http.StatusServiceUnavailable ||
+		status == http.StatusGatewayTimeout
+}
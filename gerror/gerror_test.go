@@ -0,0 +1,85 @@
+package gerror_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/browny/gogoo/gerror"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/googleapi"
+)
+
+func apiError(code int, reason string) error {
+	return &googleapi.Error{
+		Code:    code,
+		Message: "message",
+		Errors:  []googleapi.ErrorItem{googleapi.ErrorItem{Reason: reason, Message: "message"}},
+	}
+}
+
+func TestNew(t *testing.T) {
+	assert.Nil(t, gerror.New("gce", "GetVm", nil))
+
+	cause := apiError(http.StatusNotFound, "notFound")
+	err := gerror.New("gce", "GetVm", cause)
+
+	var e *gerror.Error
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, "gce", e.Service)
+	assert.Equal(t, "GetVm", e.Operation)
+	assert.Equal(t, http.StatusNotFound, e.StatusCode)
+	assert.Equal(t, "notFound", e.Reason)
+	assert.Equal(t, "GCE operation fails: GetVm: "+cause.Error(), err.Error())
+
+	var apiErr *googleapi.Error
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, cause, apiErr)
+
+	// Not wrapped twice
+	assert.Equal(t, err, gerror.New("gce", "NewVm", err))
+}
+
+func TestClassification(t *testing.T) {
+	cases := []struct {
+		err      error
+		sentinel error
+		is       func(error) bool
+		expected bool
+	}{
+		{apiError(http.StatusNotFound, "notFound"), gerror.ErrNotFound, gerror.IsNotFound, true},
+		{apiError(http.StatusBadRequest, "RESOURCE_NOT_FOUND"), gerror.ErrNotFound, gerror.IsNotFound, true},
+		{apiError(http.StatusConflict, "alreadyExists"), gerror.ErrAlreadyExists, gerror.IsAlreadyExists, true},
+		{apiError(http.StatusForbidden, "quotaExceeded"), gerror.ErrQuotaExceeded, gerror.IsQuotaExceeded, true},
+		{apiError(http.StatusForbidden, "rateLimitExceeded"), gerror.ErrQuotaExceeded, gerror.IsQuotaExceeded, false},
+		{apiError(http.StatusPreconditionFailed, "conditionNotMet"), gerror.ErrPreconditionFailed, gerror.IsPreconditionFailed, true},
+		{apiError(http.StatusForbidden, "rateLimitExceeded"), gerror.ErrRetryable, gerror.IsRetryable, true},
+		{apiError(http.StatusServiceUnavailable, "backendError"), gerror.ErrRetryable, gerror.IsRetryable, true},
+		{apiError(http.StatusTooManyRequests, ""), gerror.ErrRetryable, gerror.IsRetryable, true},
+		{apiError(http.StatusBadRequest, "invalid"), gerror.ErrRetryable, gerror.IsRetryable, false},
+		{apiError(http.StatusNotFound, "notFound"), gerror.ErrRetryable, gerror.IsRetryable, false},
+	}
+
+	for _, c := range cases {
+		wrapped := gerror.New("gce", "GetVm", c.err)
+		assert.Equal(t, c.expected, errors.Is(wrapped, c.sentinel), "%s", c.err)
+		assert.Equal(t, c.expected, c.is(wrapped), "%s", c.err)
+		// The error of google api is classified even if it is not wrapped by gogoo
+		assert.Equal(t, c.expected, c.is(c.err), "%s", c.err)
+		// and if it is wrapped by others
+		assert.Equal(t, c.expected, c.is(fmt.Errorf("outer: %w", wrapped)), "%s", c.err)
+	}
+
+	assert.False(t, gerror.IsNotFound(nil))
+	assert.False(t, gerror.IsNotFound(errors.New("not found")))
+}
+
+func TestIsRetryableContext(t *testing.T) {
+	assert.False(t, gerror.IsRetryable(gerror.New("gce", "GetVm", context.Canceled)))
+	assert.False(t, gerror.IsRetryable(gerror.New("gce", "GetVm", context.DeadlineExceeded)))
+	assert.True(t, gerror.IsRetryable(&gerror.Error{Service: "gce", Reason: gerror.ReasonOperationQuota, StatusCode: 503}))
+	assert.True(t, errors.Is(gerror.New("gce", "GetVm", context.Canceled), context.Canceled))
+}
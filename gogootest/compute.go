@@ -0,0 +1,1273 @@
+package gogootest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/browny/gogoo/utility"
+
+	compute "google.golang.org/api/compute/v1"
+)
+
+const (
+	computePrefix = "/compute/v1/projects/"
+	computeLink   = "https://www.googleapis.com/compute/v1/projects/"
+)
+
+// kindOfCollection maps the collection of compute api to the kind of its resources
+var kindOfCollection = map[string]string{
+	"instances":             "compute#instance",
+	"disks":                 "compute#disk",
+	"images":                "compute#image",
+	"snapshots":             "compute#snapshot",
+	"instanceGroups":        "compute#instanceGroup",
+	"instanceGroupManagers": "compute#instanceGroupManager",
+	"instanceTemplates":     "compute#instanceTemplate",
+	"addresses":             "compute#address",
+	"operations":            "compute#operation",
+}
+
+// computePath is the parsed path of compute api, e.g.
+// {project}/zones/{zone}/instances/{name}/{action}
+type computePath struct {
+	project   string
+	scope     string // "zones/{zone}", "regions/{region}", "global" or "aggregated"
+	scopeType string // "zones", "regions", "global" or "aggregated"
+	scopeName string // name of zone or region
+	kind      string // collection name, e.g. "instances"
+	name      string
+	action    string
+}
+
+func parseComputePath(path string) (*computePath, *apiError) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) < 3 {
+		return nil, notFound(path)
+	}
+
+	p := &computePath{project: segments[0]}
+	rest := segments[1:]
+	switch rest[0] {
+	case "zones", "regions":
+		if len(rest) < 3 {
+			return nil, notFound(path)
+		}
+		p.scopeType, p.scopeName = rest[0], rest[1]
+		p.scope = rest[0] + "/" + rest[1]
+		rest = rest[2:]
+	case "global", "aggregated":
+		p.scopeType, p.scope = rest[0], rest[0]
+		rest = rest[1:]
+	default:
+		return nil, notFound(path)
+	}
+
+	p.kind = rest[0]
+	if len(rest) > 1 {
+		p.name = rest[1]
+	}
+	if len(rest) > 2 {
+		p.action = rest[2]
+	}
+	if len(rest) > 3 {
+		return nil, notFound(path)
+	}
+
+	return p, nil
+}
+
+// collection returns the key of the collection in the store
+func (p *computePath) collection() string {
+	return p.project + "/" + p.scope + "/" + p.kind
+}
+
+// link returns the full url of the collection or the named resource
+func (p *computePath) link(name string) string {
+	if name == "" {
+		return computeLink + p.collection()
+	}
+
+	return computeLink + p.collection() + "/" + name
+}
+
+// scopeLink returns the full url of the zone or region
+func (p *computePath) scopeLink() string {
+	return computeLink + p.project + "/" + p.scope
+}
+
+// of returns the path of another collection in the same scope
+func (p *computePath) of(kind, name string) *computePath {
+	return &computePath{
+		project:   p.project,
+		scope:     p.scope,
+		scopeType: p.scopeType,
+		scopeName: p.scopeName,
+		kind:      kind,
+		name:      name,
+	}
+}
+
+// computeAction handles the custom method of a resource, returns the changed resource
+type computeAction func(server *Server, p *computePath, item, body resource) *apiError
+
+// computeActions maps "{collection}/{action}" to its handler
+var computeActions = map[string]computeAction{
+	"instances/stop": func(server *Server, p *computePath, item, body resource) *apiError {
+		server.transit(p, item, "STOPPING", "TERMINATED")
+		return nil
+	},
+	"instances/start": func(server *Server, p *computePath, item, body resource) *apiError {
+		server.transit(p, item, "PROVISIONING", "STAGING", "RUNNING")
+		return nil
+	},
+	"instances/reset": func(server *Server, p *computePath, item, body resource) *apiError {
+		return nil
+	},
+	"instances/setMachineType": func(server *Server, p *computePath, item, body resource) *apiError {
+		machineType, _ := body["machineType"].(string)
+		if machineType == "" {
+			return badRequest("machineType is required")
+		}
+		if !strings.HasPrefix(machineType, "https://") {
+			machineType = computeLink + p.project + "/" + machineType
+		}
+		item["machineType"] = machineType
+		return nil
+	},
+	"instances/setTags": func(server *Server, p *computePath, item, body resource) *apiError {
+		tags, _ := item["tags"].(map[string]interface{})
+		if err := server.checkFingerprint(tags, body); err != nil {
+			return err
+		}
+		body["fingerprint"] = server.fingerprint()
+		item["tags"] = map[string]interface{}(body)
+		return nil
+	},
+	"disks/createSnapshot": func(server *Server, p *computePath, item, body resource) *apiError {
+		body["sourceDisk"] = item["selfLink"]
+		body["sourceDiskId"] = item["id"]
+		body["diskSizeGb"] = item["sizeGb"]
+		_, err := server.insertCompute(&computePath{
+			project: p.project, scope: "global", scopeType: "global", kind: "snapshots",
+		}, body)
+		return err
+	},
+	"instances/attachDisk": func(server *Server, p *computePath, item, body resource) *apiError {
+		source, _ := body["source"].(string)
+		diskName := source[strings.LastIndex(source, "/")+1:]
+		disk, err := server.get(p.of("disks", "").collection(), diskName)
+		if err != nil {
+			return err
+		}
+		if user := server.diskUser(p, diskName); user != "" {
+			return newAPIError(http.StatusBadRequest, "resourceInUseByAnotherResource",
+				fmt.Sprintf("The disk resource '%s' is already being used by '%s'", disk["selfLink"], user))
+		}
+
+		disks, _ := item["disks"].([]interface{})
+		body["source"] = disk["selfLink"]
+		body["index"] = len(disks)
+		if _, ok := body["deviceName"]; !ok {
+			body["deviceName"] = diskName
+		}
+		if _, ok := body["mode"]; !ok {
+			body["mode"] = "READ_WRITE"
+		}
+		item["disks"] = append(disks, map[string]interface{}(body))
+		users, _ := disk["users"].([]interface{})
+		disk["users"] = append(users, item["selfLink"])
+		return nil
+	},
+	"instances/detachDisk": func(server *Server, p *computePath, item, body resource) *apiError {
+		deviceName, _ := body["deviceName"].(string)
+		disks, _ := item["disks"].([]interface{})
+		for i, d := range disks {
+			attached, _ := d.(map[string]interface{})
+			if attached["deviceName"] != deviceName {
+				continue
+			}
+			if boot, _ := attached["boot"].(bool); boot {
+				return badRequest("The boot disk can not be detached")
+			}
+			item["disks"] = append(disks[:i:i], disks[i+1:]...)
+
+			source, _ := attached["source"].(string)
+			if disk, err := server.get(p.of("disks", "").collection(), source[strings.LastIndex(source, "/")+1:]); err == nil {
+				users := []interface{}{}
+				existing, _ := disk["users"].([]interface{})
+				for _, user := range existing {
+					if user != item["selfLink"] {
+						users = append(users, user)
+					}
+				}
+				disk["users"] = users
+			}
+			return nil
+		}
+		return badRequest(fmt.Sprintf("No attached disk found with device name '%s'", deviceName))
+	},
+	"instances/addAccessConfig": func(server *Server, p *computePath, item, body resource) *apiError {
+		networkInterface, err := findNetworkInterface(item, body["networkInterface"])
+		if err != nil {
+			return err
+		}
+		delete(body, "networkInterface")
+		accessConfigs, _ := networkInterface["accessConfigs"].([]interface{})
+		if len(accessConfigs) > 0 {
+			return badRequest("At most one access config currently supported")
+		}
+		if _, ok := body["natIP"]; !ok {
+			body["natIP"] = "104.155.0." + server.nextId()
+		}
+		networkInterface["accessConfigs"] = []interface{}{map[string]interface{}(body)}
+		server.useAddress(p, body["natIP"], item["selfLink"])
+		return nil
+	},
+	"instances/deleteAccessConfig": func(server *Server, p *computePath, item, body resource) *apiError {
+		networkInterface, err := findNetworkInterface(item, body["networkInterface"])
+		if err != nil {
+			return err
+		}
+		accessConfigs, _ := networkInterface["accessConfigs"].([]interface{})
+		for i, ac := range accessConfigs {
+			accessConfig, _ := ac.(map[string]interface{})
+			if accessConfig["name"] == body["accessConfig"] {
+				networkInterface["accessConfigs"] = append(accessConfigs[:i:i], accessConfigs[i+1:]...)
+				server.useAddress(p, accessConfig["natIP"], nil)
+				return nil
+			}
+		}
+		return badRequest(fmt.Sprintf("No access config found with name '%s'", body["accessConfig"]))
+	},
+	"disks/resize": func(server *Server, p *computePath, item, body resource) *apiError {
+		size, _ := strconv.ParseInt(fmt.Sprint(body["sizeGb"]), 10, 64)
+		current, _ := strconv.ParseInt(fmt.Sprint(item["sizeGb"]), 10, 64)
+		if size <= current {
+			return badRequest(fmt.Sprintf("Requested disk size cannot be smaller than the current size (%d GB)", current))
+		}
+		item["sizeGb"] = strconv.FormatInt(size, 10)
+		return nil
+	},
+	"instances/setLabels": func(server *Server, p *computePath, item, body resource) *apiError {
+		if item["labelFingerprint"] != nil && body["labelFingerprint"] != item["labelFingerprint"] {
+			return newAPIError(http.StatusPreconditionFailed, "conditionNotMet",
+				"Labels fingerprint either invalid or resource labels have changed")
+		}
+		item["labels"] = body["labels"]
+		item["labelFingerprint"] = server.fingerprint()
+		return nil
+	},
+	"instances/setMetadata": func(server *Server, p *computePath, item, body resource) *apiError {
+		metadata, _ := item["metadata"].(map[string]interface{})
+		if err := server.checkFingerprint(metadata, body); err != nil {
+			return err
+		}
+		body["fingerprint"] = server.fingerprint()
+		item["metadata"] = map[string]interface{}(body)
+		return nil
+	},
+	"instanceGroups/addInstances": func(server *Server, p *computePath, item, body resource) *apiError {
+		members := server.groupMembers[p.link(p.name)]
+		links, err := server.groupInstances(p, body)
+		if err != nil {
+			return err
+		}
+		for _, link := range links {
+			if utility.InStringSlice(members, link) {
+				return newAPIError(http.StatusBadRequest, "memberAlreadyExists",
+					fmt.Sprintf("The instance '%s' is already a member of '%s'", link, p.link(p.name)))
+			}
+			members = append(members, link)
+		}
+		server.setGroupMembers(p.link(p.name), item, members)
+		return nil
+	},
+	"instanceGroups/removeInstances": func(server *Server, p *computePath, item, body resource) *apiError {
+		members := server.groupMembers[p.link(p.name)]
+		links, err := server.groupInstances(p, body)
+		if err != nil {
+			return err
+		}
+		for _, link := range links {
+			if !utility.InStringSlice(members, link) {
+				return newAPIError(http.StatusBadRequest, "memberNotFound",
+					fmt.Sprintf("The instance '%s' is not a member of '%s'", link, p.link(p.name)))
+			}
+		}
+		remaining := []string{}
+		for _, member := range members {
+			if !utility.InStringSlice(links, member) {
+				remaining = append(remaining, member)
+			}
+		}
+		server.setGroupMembers(p.link(p.name), item, remaining)
+		return nil
+	},
+	"instanceGroups/setNamedPorts": func(server *Server, p *computePath, item, body resource) *apiError {
+		if err := server.checkFingerprint(item, body); err != nil {
+			return err
+		}
+		item["namedPorts"] = body["namedPorts"]
+		item["fingerprint"] = server.fingerprint()
+		return nil
+	},
+	"instanceGroupManagers/resize": func(server *Server, p *computePath, item, body resource) *apiError {
+		size, err := strconv.Atoi(fmt.Sprint(body["size"]))
+		if err != nil || size < 0 {
+			return badRequest(fmt.Sprintf("Invalid value for field 'size': '%v'", body["size"]))
+		}
+		return server.resizeManager(p, item, size)
+	},
+	"instanceGroupManagers/recreateInstances": func(server *Server, p *computePath, item, body resource) *apiError {
+		groupLink := p.of("instanceGroups", p.name).link(p.name)
+		links, err := server.managedInstances(p, body)
+		if err != nil {
+			return err
+		}
+		members := server.groupMembers[groupLink]
+		for _, link := range links {
+			name := link[strings.LastIndex(link, "/")+1:]
+			server.deleteManagedInstance(p, link)
+			if _, err := server.createManagedInstance(p, item, name); err != nil {
+				return err
+			}
+			server.currentActions[link] = "RECREATING"
+		}
+		group, _ := server.get(p.of("instanceGroups", "").collection(), p.name)
+		server.setGroupMembers(groupLink, group, members)
+		return nil
+	},
+	"instanceGroupManagers/abandonInstances": func(server *Server, p *computePath, item, body resource) *apiError {
+		groupLink := p.of("instanceGroups", p.name).link(p.name)
+		links, err := server.managedInstances(p, body)
+		if err != nil {
+			return err
+		}
+		remaining := []string{}
+		for _, member := range server.groupMembers[groupLink] {
+			if !utility.InStringSlice(links, member) {
+				remaining = append(remaining, member)
+			}
+		}
+		for _, link := range links {
+			delete(server.currentActions, link)
+		}
+		group, _ := server.get(p.of("instanceGroups", "").collection(), p.name)
+		server.setGroupMembers(groupLink, group, remaining)
+		item["targetSize"] = len(remaining)
+		return nil
+	},
+}
+
+// computeQuery handles the custom method of a resource which responds the result instead of an operation
+type computeQuery func(server *Server, r *http.Request, p *computePath, item, body resource) (resource, *apiError)
+
+// computeQueries are the supported queries by "{kind}/{action}"
+var computeQueries = map[string]computeQuery{
+	"instanceGroups/listInstances": func(
+		server *Server, r *http.Request, p *computePath, item, body resource) (resource, *apiError) {
+
+		members := []resource{}
+		for _, link := range server.groupMembers[p.link(p.name)] {
+			instance, err := server.get(p.of("instances", "").collection(), link[strings.LastIndex(link, "/")+1:])
+			if err != nil {
+				continue
+			}
+			if body["instanceState"] == "RUNNING" && instance["status"] != "RUNNING" {
+				continue
+			}
+			members = append(members, resource{
+				"instance":   link,
+				"status":     instance["status"],
+				"namedPorts": item["namedPorts"],
+			})
+		}
+		items, nextPageToken := paginate(r, members, server.pageSize)
+
+		return resource{
+			"kind":          "compute#instanceGroupsListInstances",
+			"selfLink":      p.link(p.name),
+			"items":         items,
+			"nextPageToken": nextPageToken,
+		}, nil
+	},
+	"instanceGroupManagers/listManagedInstances": func(
+		server *Server, r *http.Request, p *computePath, item, body resource) (resource, *apiError) {
+
+		managed := []resource{}
+		for _, link := range server.groupMembers[p.of("instanceGroups", p.name).link(p.name)] {
+			// The instances step through the transient statuses as they are polled by the list
+			instance, err := server.getCompute(p.of("instances", link[strings.LastIndex(link, "/")+1:]))
+			if err != nil {
+				continue
+			}
+			action := "NONE"
+			if status := instance["status"]; status == "PROVISIONING" || status == "STAGING" {
+				action = "CREATING"
+				if recreating, ok := server.currentActions[link]; ok {
+					action = recreating
+				}
+			} else {
+				delete(server.currentActions, link)
+			}
+			managed = append(managed, resource{
+				"instance":       link,
+				"id":             instance["id"],
+				"instanceStatus": instance["status"],
+				"currentAction":  action,
+			})
+		}
+
+		return resource{"managedInstances": managed}, nil
+	},
+}
+
+// initManager creates the instance group of the new managed instance group, and the instances of
+// its target size from its instance template
+func (server *Server) initManager(p *computePath, item resource) *apiError {
+	templateLink, _ := item["instanceTemplate"].(string)
+	templateName := templateLink[strings.LastIndex(templateLink, "/")+1:]
+	template, err := server.get(projectOfLink(templateLink, p.project)+"/global/instanceTemplates", templateName)
+	if err != nil {
+		return err
+	}
+	item["instanceTemplate"] = template["selfLink"]
+	if _, ok := item["baseInstanceName"]; !ok {
+		item["baseInstanceName"] = item["name"]
+	}
+
+	group, err := server.insertCompute(p.of("instanceGroups", ""), resource{
+		"name":       item["name"],
+		"namedPorts": item["namedPorts"],
+	})
+	if err != nil {
+		return err
+	}
+	item["instanceGroup"] = group["selfLink"]
+	item["fingerprint"] = server.fingerprint()
+
+	size, _ := strconv.Atoi(fmt.Sprint(item["targetSize"]))
+
+	return server.resizeManager(p.of(p.kind, item["name"].(string)), item, size)
+}
+
+// resizeManager creates or deletes the instances of the managed instance group to the size,
+// the newest instances are deleted first
+func (server *Server) resizeManager(p *computePath, manager resource, size int) *apiError {
+	groupLink := p.of("instanceGroups", p.name).link(p.name)
+	for len(server.groupMembers[groupLink]) > size {
+		members := server.groupMembers[groupLink]
+		server.deleteManagedInstance(p, members[len(members)-1])
+	}
+	for len(server.groupMembers[groupLink]) < size {
+		link, err := server.createManagedInstance(p, manager, "")
+		if err != nil {
+			return err
+		}
+		group, _ := server.get(p.of("instanceGroups", "").collection(), p.name)
+		server.setGroupMembers(groupLink, group, append(server.groupMembers[groupLink], link))
+	}
+	manager["targetSize"] = size
+
+	return nil
+}
+
+// createManagedInstance creates the instance from the instance template of the managed instance group,
+// it is named after the base instance name if name is empty
+func (server *Server) createManagedInstance(p *computePath, manager resource, name string) (string, *apiError) {
+	templateLink, _ := manager["instanceTemplate"].(string)
+	template, err := server.get(projectOfLink(templateLink, p.project)+"/global/instanceTemplates",
+		templateLink[strings.LastIndex(templateLink, "/")+1:])
+	if err != nil {
+		return "", err
+	}
+
+	item := toResource(template["properties"])
+	if name == "" {
+		name = fmt.Sprintf("%s-%s", manager["baseInstanceName"], server.nextId())
+	}
+	item["name"] = name
+	if machineType, _ := item["machineType"].(string); machineType != "" && !strings.Contains(machineType, "/") {
+		item["machineType"] = p.scope + "/machineTypes/" + machineType
+	}
+	created, err := server.insertCompute(p.of("instances", ""), item)
+	if err != nil {
+		return "", err
+	}
+
+	return created["selfLink"].(string), nil
+}
+
+// deleteManagedInstance deletes the instance of the managed instance group at once
+func (server *Server) deleteManagedInstance(p *computePath, link string) {
+	instancePath := p.of("instances", link[strings.LastIndex(link, "/")+1:])
+	server.deleteCompute(instancePath)
+	delete(server.pendingDeletions, instancePath.collection()+"/"+instancePath.name)
+	server.delete(instancePath.collection(), instancePath.name)
+	server.leaveGroups(link)
+}
+
+// managedInstances resolves the instances of the request of managed instance group to their urls,
+// the instances have to be managed by the group
+func (server *Server) managedInstances(p *computePath, body resource) ([]string, *apiError) {
+	members := server.groupMembers[p.of("instanceGroups", p.name).link(p.name)]
+	links := []string{}
+	instances, _ := body["instances"].([]interface{})
+	for _, i := range instances {
+		instance, _ := i.(string)
+		link := p.of("instances", "").link(instance[strings.LastIndex(instance, "/")+1:])
+		if !utility.InStringSlice(members, link) {
+			return nil, badRequest(fmt.Sprintf("The instance '%s' is not managed by '%s'", instance, p.link(p.name)))
+		}
+		links = append(links, link)
+	}
+
+	return links, nil
+}
+
+// groupInstances resolves the instances referenced by the request of instance group to their urls,
+// the instances have to be in the zone of the group
+func (server *Server) groupInstances(p *computePath, body resource) ([]string, *apiError) {
+	links := []string{}
+	references, _ := body["instances"].([]interface{})
+	for _, r := range references {
+		reference, _ := r.(map[string]interface{})
+		link, _ := reference["instance"].(string)
+		instance, err := server.get(p.of("instances", "").collection(), link[strings.LastIndex(link, "/")+1:])
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, instance["selfLink"].(string))
+	}
+
+	return links, nil
+}
+
+// setGroupMembers sets the members of the instance group and its size
+func (server *Server) setGroupMembers(groupLink string, group resource, members []string) {
+	server.groupMembers[groupLink] = members
+	group["size"] = len(members)
+}
+
+// leaveGroups removes the instance from all instance groups
+func (server *Server) leaveGroups(instanceLink string) {
+	for groupLink, members := range server.groupMembers {
+		key := strings.TrimPrefix(groupLink, computeLink)
+		group, err := server.get(key[:strings.LastIndex(key, "/")], key[strings.LastIndex(key, "/")+1:])
+		if err != nil || !utility.InStringSlice(members, instanceLink) {
+			continue
+		}
+		remaining := []string{}
+		for _, member := range members {
+			if member != instanceLink {
+				remaining = append(remaining, member)
+			}
+		}
+		server.setGroupMembers(groupLink, group, remaining)
+	}
+}
+
+// findNetworkInterface finds the network interface of instance by its name, e.g. "nic0"
+func findNetworkInterface(instance resource, name interface{}) (map[string]interface{}, *apiError) {
+	interfaces, _ := instance["networkInterfaces"].([]interface{})
+	for _, nic := range interfaces {
+		if networkInterface, _ := nic.(map[string]interface{}); networkInterface["name"] == name {
+			return networkInterface, nil
+		}
+	}
+
+	return nil, badRequest(fmt.Sprintf("No network interface found with name '%s'", name))
+}
+
+func (server *Server) serveCompute(r *http.Request, path string) (interface{}, *apiError) {
+	p, err := parseComputePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.scopeType == "aggregated":
+		if r.Method != "GET" || p.name != "" {
+			return nil, notFound(path)
+		}
+		return server.aggregatedList(r, p)
+	case r.Method == "GET" && p.name == "":
+		match, err := parseFilter(r.URL.Query().Get("filter"))
+		if err != nil {
+			return nil, err
+		}
+		items, nextPageToken := server.list(r, p.collection(), match)
+		return resource{
+			"kind":          kindOfCollection[p.kind] + "List",
+			"selfLink":      p.link(""),
+			"items":         items,
+			"nextPageToken": nextPageToken,
+		}, nil
+	case r.Method == "GET" && p.kind == "operations" && p.action == "":
+		return server.pollOperation(p)
+	case r.Method == "GET" && p.action == "":
+		return server.getCompute(p)
+	case r.Method == "POST" && p.name == "":
+		body, err := decode(r)
+		if err != nil {
+			return nil, err
+		}
+		name, _ := body["name"].(string)
+		if op := server.failedOperation(p, "insert", p.link(name)); op != nil {
+			return op, nil
+		}
+		item, err := server.insertCompute(p, body)
+		if err != nil {
+			return nil, err
+		}
+		return server.computeOperation(p, "insert", item["selfLink"].(string)), nil
+	case r.Method == "DELETE" && p.action == "":
+		if op := server.failedOperation(p, "delete", p.link(p.name)); op != nil {
+			return op, nil
+		}
+		if err := server.deleteCompute(p); err != nil {
+			return nil, err
+		}
+		return server.computeOperation(p, "delete", p.link(p.name)), nil
+	case p.action != "":
+		action, isAction := computeActions[p.kind+"/"+p.action]
+		query, isQuery := computeQueries[p.kind+"/"+p.action]
+		if !isAction && !isQuery {
+			return nil, notFound(path)
+		}
+		item, err := server.get(p.collection(), p.name)
+		if err != nil {
+			return nil, err
+		}
+		body, err := decode(r)
+		if err != nil {
+			return nil, err
+		}
+		// Some actions take the parameters by the query, e.g. deviceName of detachDisk
+		for _, key := range []string{"deviceName", "networkInterface", "accessConfig", "size"} {
+			if value := r.URL.Query().Get(key); value != "" {
+				body[key] = value
+			}
+		}
+		if isQuery {
+			return query(server, r, p, item, body)
+		}
+		if op := server.failedOperation(p, p.action, p.link(p.name)); op != nil {
+			return op, nil
+		}
+		if err := action(server, p, item, body); err != nil {
+			return nil, err
+		}
+		return server.computeOperation(p, p.action, p.link(p.name)), nil
+	}
+
+	return nil, newAPIError(http.StatusMethodNotAllowed, "badRequest", r.Method+" "+path)
+}
+
+// getCompute gets the resource, the resource being deleted is gone after `deletionPolls` gets
+func (server *Server) getCompute(p *computePath) (resource, *apiError) {
+	key := p.collection() + "/" + p.name
+	if polls, ok := server.pendingDeletions[key]; ok {
+		if polls--; polls > 0 {
+			server.pendingDeletions[key] = polls
+		} else {
+			delete(server.pendingDeletions, key)
+			server.delete(p.collection(), p.name)
+		}
+	}
+
+	item, err := server.get(p.collection(), p.name)
+	if err != nil {
+		return nil, err
+	}
+
+	// The status responded is the current one, and the next get sees the next status
+	got := resource{}
+	merge(got, item)
+	if statuses := server.pendingStatuses[key]; len(statuses) > 0 {
+		item["status"] = statuses[0]
+		server.pendingStatuses[key] = statuses[1:]
+	}
+
+	return got, nil
+}
+
+// transit sets the status of the resource to the last one, or steps through the statuses
+// one per get if the transitions are enabled by SetStatusTransitions
+func (server *Server) transit(p *computePath, item resource, statuses ...string) {
+	key := p.collection() + "/" + p.name
+	if !server.transitions {
+		item["status"] = statuses[len(statuses)-1]
+		delete(server.pendingStatuses, key)
+		return
+	}
+
+	item["status"] = statuses[0]
+	server.pendingStatuses[key] = statuses[1:]
+}
+
+// deleteCompute deletes the resource. The disks attached to an instance can not be deleted,
+// and the disks of a deleted instance are deleted along with it by their `autoDelete`.
+func (server *Server) deleteCompute(p *computePath) *apiError {
+	item, err := server.get(p.collection(), p.name)
+	if err != nil {
+		return err
+	}
+	if _, ok := server.pendingDeletions[p.collection()+"/"+p.name]; ok {
+		return newAPIError(http.StatusBadRequest, "resourceNotReady",
+			fmt.Sprintf("The resource '%s' is being deleted", p.link(p.name)))
+	}
+
+	switch p.kind {
+	case "disks":
+		if user := server.diskUser(p, p.name); user != "" {
+			return newAPIError(http.StatusBadRequest, "resourceInUseByAnotherResource",
+				fmt.Sprintf("The disk resource '%s' is already being used by '%s'", p.link(p.name), user))
+		}
+	case "addresses":
+		if users, _ := item["users"].([]interface{}); len(users) > 0 {
+			return newAPIError(http.StatusBadRequest, "resourceInUseByAnotherResource",
+				fmt.Sprintf("The address resource '%s' is already being used by '%s'", p.link(p.name), users[0]))
+		}
+	case "instanceGroups":
+		delete(server.groupMembers, p.link(p.name))
+	case "instanceGroupManagers":
+		// The instances are deleted along with the managed instance group
+		groupPath := p.of("instanceGroups", p.name)
+		for _, link := range server.groupMembers[groupPath.link(p.name)] {
+			server.deleteManagedInstance(p, link)
+		}
+		delete(server.groupMembers, groupPath.link(p.name))
+		server.delete(groupPath.collection(), p.name)
+	case "instances":
+		server.leaveGroups(p.link(p.name))
+		interfaces, _ := item["networkInterfaces"].([]interface{})
+		for _, nic := range interfaces {
+			networkInterface, _ := nic.(map[string]interface{})
+			accessConfigs, _ := networkInterface["accessConfigs"].([]interface{})
+			for _, ac := range accessConfigs {
+				accessConfig, _ := ac.(map[string]interface{})
+				server.useAddress(p, accessConfig["natIP"], nil)
+			}
+		}
+		disks, _ := item["disks"].([]interface{})
+		for _, d := range disks {
+			disk, _ := d.(map[string]interface{})
+			source, _ := disk["source"].(string)
+			if autoDelete, _ := disk["autoDelete"].(bool); autoDelete && source != "" {
+				server.delete(p.of("disks", "").collection(), source[strings.LastIndex(source, "/")+1:])
+			}
+		}
+	}
+	server.removeCompute(p)
+
+	return nil
+}
+
+// removeCompute removes the resource now, or after `deletionPolls` gets
+func (server *Server) removeCompute(p *computePath) {
+	if server.deletionPolls <= 0 {
+		server.delete(p.collection(), p.name)
+		return
+	}
+
+	if item, err := server.get(p.collection(), p.name); err == nil {
+		item["status"] = "DELETING"
+		if p.kind == "instances" {
+			item["status"] = "STOPPING"
+		}
+		server.pendingDeletions[p.collection()+"/"+p.name] = server.deletionPolls
+	}
+}
+
+// diskUser returns the link of the instance using the disk, or empty string if it is not attached
+func (server *Server) diskUser(p *computePath, diskName string) string {
+	for _, instance := range server.collection(p.of("instances", "").collection()) {
+		disks, _ := instance["disks"].([]interface{})
+		for _, d := range disks {
+			disk, _ := d.(map[string]interface{})
+			source, _ := disk["source"].(string)
+			if source != "" && source[strings.LastIndex(source, "/")+1:] == diskName {
+				return instance["selfLink"].(string)
+			}
+		}
+	}
+
+	return ""
+}
+
+// aggregatedList lists the resources of all zones grouped by zone, the pages are cut
+// across the zones ordered by zone and name
+func (server *Server) aggregatedList(r *http.Request, p *computePath) (interface{}, *apiError) {
+	match, err := parseFilter(r.URL.Query().Get("filter"))
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, suffix := p.project+"/zones/", "/"+p.kind
+	collections := []string{}
+	for collection := range server.collections {
+		if strings.HasPrefix(collection, prefix) && strings.HasSuffix(collection, suffix) {
+			collections = append(collections, collection)
+		}
+	}
+	sort.Strings(collections)
+
+	items := []resource{}
+	for _, collection := range collections {
+		items = append(items, server.sorted(collection, match)...)
+	}
+	items, nextPageToken := paginate(r, items, server.pageSize)
+
+	scoped := map[string]interface{}{}
+	for _, item := range items {
+		zone, _ := item["zone"].(string)
+		scope := "zones/" + zone[strings.LastIndex(zone, "/")+1:]
+		group, _ := scoped[scope].(resource)
+		if group == nil {
+			group = resource{p.kind: []resource{}}
+			scoped[scope] = group
+		}
+		group[p.kind] = append(group[p.kind].([]resource), item)
+	}
+
+	return resource{
+		"kind":          strings.Replace(kindOfCollection[p.kind], "#", "#aggregated", 1),
+		"selfLink":      computeLink + p.project + "/aggregated/" + p.kind,
+		"items":         scoped,
+		"nextPageToken": nextPageToken,
+	}, nil
+}
+
+// insertCompute fills the output only fields of the new resource and stores it
+func (server *Server) insertCompute(p *computePath, item resource) (resource, *apiError) {
+	name, _ := item["name"].(string)
+	if name == "" {
+		return nil, badRequest("name is required")
+	}
+
+	item["kind"] = kindOfCollection[p.kind]
+	item["id"] = server.nextId()
+	item["creationTimestamp"] = now()
+	item["selfLink"] = p.link(name)
+	switch p.scopeType {
+	case "zones":
+		item["zone"] = p.scopeLink()
+	case "regions":
+		item["region"] = p.scopeLink()
+	}
+
+	switch p.kind {
+	case "instances":
+		server.initInstance(p, item)
+	case "disks":
+		if err := server.initDisk(p, item); err != nil {
+			return nil, err
+		}
+		if _, ok := item["status"]; !ok {
+			server.transit(p.of(p.kind, name), item, "CREATING", "READY")
+		}
+	case "images":
+		if _, ok := item["status"]; !ok {
+			server.transit(p.of(p.kind, name), item, "CREATING", "READY")
+		}
+	case "snapshots":
+		if _, ok := item["status"]; !ok {
+			server.transit(p.of(p.kind, name), item, "CREATING", "UPLOADING", "READY")
+		}
+	case "instanceGroups":
+		item["size"] = 0
+		item["fingerprint"] = server.fingerprint()
+	case "instanceGroupManagers":
+		if err := server.initManager(p.of(p.kind, name), item); err != nil {
+			return nil, err
+		}
+	case "addresses":
+		server.initAddress(p, item)
+	}
+
+	if err := server.insert(p.collection(), name, item); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// initDisk fills the type and size of new disk, and resolves the image family to its latest image
+func (server *Server) initDisk(p *computePath, item resource) *apiError {
+	diskType, _ := item["type"].(string)
+	if diskType == "" {
+		diskType = "pd-standard"
+	}
+	if !strings.Contains(diskType, "/") {
+		diskType = p.scope + "/diskTypes/" + diskType
+	}
+	if !strings.HasPrefix(diskType, "https://") {
+		diskType = computeLink + p.project + "/" + diskType
+	}
+	item["type"] = diskType
+
+	var source resource
+	if sourceImage, _ := item["sourceImage"].(string); strings.Contains(sourceImage, "/family/") {
+		project := projectOfLink(sourceImage, p.project)
+		family := sourceImage[strings.LastIndex(sourceImage, "/")+1:]
+		for _, image := range server.sorted(project+"/global/images", nil) {
+			if image["family"] != family || image["deprecated"] != nil {
+				continue
+			}
+			if source == nil || image["creationTimestamp"].(string) >= source["creationTimestamp"].(string) {
+				source = image
+			}
+		}
+		if source == nil {
+			return notFound(sourceImage)
+		}
+		item["sourceImage"], item["sourceImageId"] = source["selfLink"], source["id"]
+	}
+	if sourceSnapshot, _ := item["sourceSnapshot"].(string); sourceSnapshot != "" {
+		source, _ = server.get(projectOfLink(sourceSnapshot, p.project)+"/global/snapshots",
+			sourceSnapshot[strings.LastIndex(sourceSnapshot, "/")+1:])
+	}
+
+	if item["sizeGb"] == nil {
+		item["sizeGb"] = "10"
+		if source != nil && source["diskSizeGb"] != nil {
+			item["sizeGb"] = source["diskSizeGb"]
+		}
+	}
+
+	return nil
+}
+
+// initAddress allocates the IP of new static address, or promotes the ephemeral IP in use by an instance
+func (server *Server) initAddress(p *computePath, item resource) {
+	item["status"] = "RESERVED"
+	if _, ok := item["address"]; !ok {
+		item["address"] = "35.185.0." + server.nextId()
+		return
+	}
+
+	for collection := range server.collections {
+		if !strings.HasPrefix(collection, p.project+"/zones/"+p.scopeName+"-") ||
+			!strings.HasSuffix(collection, "/instances") {
+			continue
+		}
+		for _, instance := range server.collection(collection) {
+			if instanceUsesIP(instance, item["address"]) {
+				item["status"], item["users"] = "IN_USE", []interface{}{instance["selfLink"]}
+			}
+		}
+	}
+}
+
+// useAddress marks the static address of the ip in the region of zone p as used by the user,
+// or as reserved if user is nil
+func (server *Server) useAddress(p *computePath, ip, user interface{}) {
+	region := p.scopeName[:strings.LastIndex(p.scopeName, "-")]
+	for _, address := range server.collection(p.project + "/regions/" + region + "/addresses") {
+		if address["address"] != ip {
+			continue
+		}
+		if user == nil {
+			address["status"] = "RESERVED"
+			delete(address, "users")
+		} else {
+			address["status"], address["users"] = "IN_USE", []interface{}{user}
+		}
+	}
+}
+
+func instanceUsesIP(instance resource, ip interface{}) bool {
+	interfaces, _ := instance["networkInterfaces"].([]interface{})
+	for _, nic := range interfaces {
+		networkInterface, _ := nic.(map[string]interface{})
+		accessConfigs, _ := networkInterface["accessConfigs"].([]interface{})
+		for _, ac := range accessConfigs {
+			if accessConfig, _ := ac.(map[string]interface{}); accessConfig["natIP"] == ip {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// projectOfLink gets the project of the partial or full url of a resource, or the default project
+func projectOfLink(link, defaultProject string) string {
+	segments := strings.Split(link, "/")
+	for i := 0; i < len(segments)-1; i++ {
+		if segments[i] == "projects" {
+			return segments[i+1]
+		}
+	}
+
+	return defaultProject
+}
+
+// initInstance fills the fields of new instance as compute engine does
+func (server *Server) initInstance(p *computePath, item resource) {
+	if _, ok := item["status"]; !ok {
+		server.transit(p.of(p.kind, item["name"].(string)), item, "PROVISIONING", "STAGING", "RUNNING")
+	}
+	if machineType, ok := item["machineType"].(string); ok && !strings.HasPrefix(machineType, "https://") {
+		item["machineType"] = computeLink + p.project + "/" + machineType
+	}
+
+	tags, _ := item["tags"].(map[string]interface{})
+	if tags == nil {
+		tags = map[string]interface{}{}
+		item["tags"] = tags
+	}
+	tags["fingerprint"] = server.fingerprint()
+	metadata, _ := item["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+		item["metadata"] = metadata
+	}
+	metadata["fingerprint"] = server.fingerprint()
+	item["labelFingerprint"] = server.fingerprint()
+
+	interfaces, _ := item["networkInterfaces"].([]interface{})
+	for i, nic := range interfaces {
+		networkInterface, _ := nic.(map[string]interface{})
+		if networkInterface == nil {
+			continue
+		}
+		networkInterface["name"] = fmt.Sprintf("nic%d", i)
+		if _, ok := networkInterface["networkIP"]; !ok {
+			networkInterface["networkIP"] = "10.240.0." + server.nextId()
+		}
+		accessConfigs, _ := networkInterface["accessConfigs"].([]interface{})
+		for _, ac := range accessConfigs {
+			accessConfig, _ := ac.(map[string]interface{})
+			if accessConfig == nil {
+				continue
+			}
+			if _, ok := accessConfig["natIP"]; !ok {
+				accessConfig["natIP"] = "104.155.0." + server.nextId()
+			}
+			if _, ok := accessConfig["name"]; !ok {
+				accessConfig["name"] = "External NAT"
+			}
+			server.useAddress(p, accessConfig["natIP"], item["selfLink"])
+		}
+	}
+
+	// Boot disk is created along with the instance
+	disks, _ := item["disks"].([]interface{})
+	for i, d := range disks {
+		disk, _ := d.(map[string]interface{})
+		if disk == nil {
+			continue
+		}
+		disk["index"] = i
+		if _, ok := disk["deviceName"]; !ok {
+			disk["deviceName"] = fmt.Sprintf("persistent-disk-%d", i)
+		}
+		params, _ := disk["initializeParams"].(map[string]interface{})
+		if params == nil {
+			continue
+		}
+		diskName, _ := params["diskName"].(string)
+		if diskName == "" {
+			diskName = item["name"].(string)
+		}
+		created, err := server.insertCompute(p.of("disks", ""), resource{
+			"name":        diskName,
+			"sizeGb":      params["diskSizeGb"],
+			"sourceImage": params["sourceImage"],
+			"users":       []interface{}{p.link(item["name"].(string))},
+		})
+		if err == nil {
+			disk["source"] = created["selfLink"]
+			delete(disk, "initializeParams")
+		}
+	}
+}
+
+// computeOperation creates the operation of the target, it is DONE unless SetOperationPolls is set
+func (server *Server) computeOperation(p *computePath, operationType, targetLink string) resource {
+	id := server.nextId()
+	name := "operation-" + id
+	operations := p.of("operations", "")
+
+	op := resource{
+		"kind":          "compute#operation",
+		"id":            id,
+		"name":          name,
+		"operationType": operationType,
+		"targetLink":    targetLink,
+		"status":        "DONE",
+		"progress":      100,
+		"insertTime":    now(),
+		"startTime":     now(),
+		"endTime":       now(),
+		"selfLink":      operations.link(name),
+	}
+	switch p.scopeType {
+	case "zones":
+		op["zone"] = p.scopeLink()
+	case "regions":
+		op["region"] = p.scopeLink()
+	}
+	if server.operationPolls > 0 {
+		op["status"], op["progress"] = "RUNNING", 0
+		delete(op, "endTime")
+		server.pendingOperations[operations.collection()+"/"+name] = server.operationPolls
+	}
+	server.collection(operations.collection())[name] = op
+
+	return op
+}
+
+// failedOperation creates a DONE operation with the error given by FailNextOperation,
+// it returns nil if there is no failure to inject
+func (server *Server) failedOperation(p *computePath, operationType, targetLink string) resource {
+	if len(server.operationFailures) == 0 {
+		return nil
+	}
+
+	failure := server.operationFailures[0]
+	server.operationFailures = server.operationFailures[1:]
+
+	op := server.computeOperation(p, operationType, targetLink)
+	op["error"] = resource{"errors": []interface{}{failure}}
+	op["httpErrorStatusCode"] = http.StatusBadRequest
+	op["httpErrorMessage"] = "BAD REQUEST"
+
+	return op
+}
+
+// pollOperation gets the operation, the RUNNING operation becomes DONE after the configured polls
+func (server *Server) pollOperation(p *computePath) (resource, *apiError) {
+	op, err := server.get(p.collection(), p.name)
+	if err != nil {
+		return nil, err
+	}
+
+	key := p.collection() + "/" + p.name
+	if polls, ok := server.pendingOperations[key]; ok {
+		if polls--; polls > 0 {
+			server.pendingOperations[key] = polls
+		} else {
+			delete(server.pendingOperations, key)
+			op["status"], op["progress"], op["endTime"] = "DONE", 100, now()
+		}
+	}
+
+	return op, nil
+}
+
+// fingerprint generates a new fingerprint of tags, labels or metadata
+func (server *Server) fingerprint() string {
+	return "fp-" + server.nextId()
+}
+
+// checkFingerprint checks the fingerprint in request matches the current one
+func (server *Server) checkFingerprint(current map[string]interface{}, body resource) *apiError {
+	if current == nil || current["fingerprint"] == nil {
+		return nil
+	}
+	if body["fingerprint"] != current["fingerprint"] {
+		return newAPIError(http.StatusPreconditionFailed, "conditionNotMet",
+			"Supplied fingerprint does not match current metadata fingerprint.")
+	}
+
+	return nil
+}
+
+func (server *Server) seedCompute(p *computePath, object interface{}) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	item := toResource(object)
+	created, _ := item["creationTimestamp"].(string)
+	if _, err := server.insertCompute(p, item); err != nil {
+		panic(err.Message)
+	}
+	// The seeded resources may be created in the past
+	if created != "" {
+		item["creationTimestamp"] = created
+	}
+}
+
+// SetOperationPolls makes the new operations of compute engine stay RUNNING for the first n polls
+func (server *Server) SetOperationPolls(n int) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	server.operationPolls = n
+}
+
+// SetStatusTransitions makes the instances and disks step through the transient statuses, one per get,
+// e.g. a new instance is PROVISIONING, STAGING and then RUNNING, and a stopped one is STOPPING and then TERMINATED
+func (server *Server) SetStatusTransitions(enabled bool) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	server.transitions = enabled
+}
+
+// SetDeletionPolls makes the deleted resources of compute engine stay for the next n gets before they are gone
+func (server *Server) SetDeletionPolls(n int) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	server.deletionPolls = n
+}
+
+// FailNextOperation makes the next mutation of compute engine fail. Its operation ends with
+// the error of the code, e.g. "QUOTA_EXCEEDED", and the mutation is not applied.
+func (server *Server) FailNextOperation(code, message string) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	server.operationFailures = append(server.operationFailures, resource{"code": code, "message": message})
+}
+
+// AddInstance adds an instance into the zone, its status is RUNNING if not specified
+func (server *Server) AddInstance(zone string, vm *compute.Instance) {
+	server.seedCompute(&computePath{
+		project: ProjectId, scope: "zones/" + zone, scopeType: "zones", scopeName: zone, kind: "instances",
+	}, vm)
+}
+
+// AddDisk adds a disk into the zone, its status is READY if not specified
+func (server *Server) AddDisk(zone string, disk *compute.Disk) {
+	server.seedCompute(&computePath{
+		project: ProjectId, scope: "zones/" + zone, scopeType: "zones", scopeName: zone, kind: "disks",
+	}, disk)
+}
+
+// AddImage adds an image into the project
+func (server *Server) AddImage(image *compute.Image) {
+	server.seedCompute(&computePath{
+		project: ProjectId, scope: "global", scopeType: "global", kind: "images",
+	}, image)
+}
+
+// AddSnapshot adds a snapshot into the project, its status is READY if not specified,
+// and its creationTimestamp is kept if specified
+func (server *Server) AddSnapshot(snapshot *compute.Snapshot) {
+	server.seedCompute(&computePath{
+		project: ProjectId, scope: "global", scopeType: "global", kind: "snapshots",
+	}, snapshot)
+}
+
+// AddInstanceGroup adds an unmanaged instance group into the zone
+func (server *Server) AddInstanceGroup(zone string, group *compute.InstanceGroup) {
+	server.seedCompute(&computePath{
+		project: ProjectId, scope: "zones/" + zone, scopeType: "zones", scopeName: zone, kind: "instanceGroups",
+	}, group)
+}
+
+// InstanceGroupMembers returns the urls of the instances added into the group
+func (server *Server) InstanceGroupMembers(zone, group string) []string {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	return server.groupMembers[computeLink+ProjectId+"/zones/"+zone+"/instanceGroups/"+group]
+}
@@ -0,0 +1,85 @@
+package gogootest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// filterPattern matches an expression of the regular expression form of compute engine filters,
+// e.g. `(name eq "instance-.*")`, `(labels.env ne 'prod')`
+var filterPattern = regexp.MustCompile(`^\s*\(\s*([\w.]+)\s+(eq|ne)\s+("(?:[^"\\]|\\.)*"|'[^']*'|[^\s)]+)\s*\)`)
+
+// parseFilter parses the `filter` of the compute list requests into the matcher of resources,
+// it returns nil if there is no filter
+func parseFilter(filter string) (func(resource) bool, *apiError) {
+	filter = strings.TrimSpace(filter)
+	if filter == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(filter, "(") {
+		filter = "(" + filter + ")"
+	}
+
+	matchers := []func(resource) bool{}
+	for strings.TrimSpace(filter) != "" {
+		m := filterPattern.FindStringSubmatch(filter)
+		if m == nil {
+			return nil, badRequest(fmt.Sprintf("Invalid value for field 'filter': '%s'", filter))
+		}
+		filter = filter[len(m[0]):]
+
+		literal := m[3]
+		if strings.HasPrefix(literal, `"`) || strings.HasPrefix(literal, `'`) {
+			literal = strings.Replace(literal[1:len(literal)-1], `\"`, `"`, -1)
+		}
+		re, err := regexp.Compile("^(?:" + literal + ")$")
+		if err != nil {
+			return nil, badRequest(fmt.Sprintf("Invalid regular expression '%s' in filter", literal))
+		}
+
+		field, negated := m[1], m[2] == "ne"
+		matchers = append(matchers, func(item resource) bool {
+			matched := false
+			for _, value := range fieldValues(item, field) {
+				matched = matched || re.MatchString(value)
+			}
+			return matched != negated
+		})
+	}
+
+	return func(item resource) bool {
+		for _, match := range matchers {
+			if !match(item) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// fieldValues gets the values of the dotted field, the elements of an array are all returned
+// and a missing field is taken as the empty string
+func fieldValues(item resource, field string) []string {
+	var value interface{} = map[string]interface{}(item)
+	for _, key := range strings.Split(field, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return []string{""}
+		}
+		value = object[key]
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return []string{""}
+	case []interface{}:
+		values := []string{}
+		for _, element := range v {
+			values = append(values, fmt.Sprint(element))
+		}
+		return values
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
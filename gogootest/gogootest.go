@@ -0,0 +1,405 @@
+// Package gogootest provides in-process fakes of the google cloud REST apis used by gogoo,
+// so the code built on gogoo can be tested without network and credentials.
+//
+// The fakes cover compute engine, cloud sql, pub/sub, storage, replicapoolupdater and
+// cloud monitoring. Datastore is not faked, so the GoGoo built by this package has no GdsManager.
+package gogootest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/browny/gogoo"
+	"github.com/browny/gogoo/auth"
+	"github.com/browny/gogoo/gce"
+	"github.com/browny/gogoo/retry"
+)
+
+// ProjectId is the project served by the fakes
+const ProjectId = "gogootest"
+
+// RetryPolicy retries the failures injected by FailNextRequests without slowing down the tests
+var RetryPolicy = retry.Policy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     10 * time.Millisecond,
+}
+
+// PollInterval is the interval the GoGoo built by the server polls the operations and statuses
+var PollInterval = 10 * time.Millisecond
+
+// Components lists the components backed by the fakes
+var Components = []gogoo.Component{
+	gogoo.Gce,
+	gogoo.Gcm,
+	gogoo.CloudSql,
+	gogoo.Rpu,
+	gogoo.Pbsb,
+	gogoo.Storage,
+}
+
+// resource is the JSON object representation of an api resource
+type resource map[string]interface{}
+
+// Server is the http server serving all fakes
+type Server struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	collections  map[string]map[string]resource
+	groupMembers map[string][]string
+	// currentActions are the actions of the managed instances by their urls, e.g. RECREATING
+	currentActions map[string]string
+	sequence       int
+
+	// operationPolls is the number of polls a new compute operation stays RUNNING
+	operationPolls    int
+	pendingOperations map[string]int
+	// deletionPolls is the number of gets a deleted compute resource stays before it is gone
+	deletionPolls    int
+	pendingDeletions map[string]int
+	// transitions makes the compute resources step through the transient statuses
+	transitions     bool
+	pendingStatuses map[string][]string
+	// operationFailures are the errors of the next failed compute operations
+	operationFailures []resource
+	// requestFailures are the errors responded to the next requests
+	requestFailures []*apiError
+	// responseFailures are the errors responded to the next requests after they are served
+	responseFailures []*apiError
+	requests         int
+	// pageSize is the page size of the lists if `maxResults` is not given
+	pageSize int
+	// servedRequests are the responses of the compute requests by their `requestId`
+	servedRequests map[string]interface{}
+}
+
+// NewServer starts a new fake server. It should be closed by the caller.
+func NewServer() *Server {
+	server := &Server{
+		collections:       map[string]map[string]resource{},
+		groupMembers:      map[string][]string{},
+		currentActions:    map[string]string{},
+		pendingOperations: map[string]int{},
+		pendingDeletions:  map[string]int{},
+		pendingStatuses:   map[string][]string{},
+		servedRequests:    map[string]interface{}{},
+	}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.serveHTTP))
+
+	return server
+}
+
+// New returns a GoGoo connected to a new fake server which lives until the test process ends
+func New() *gogoo.GoGoo {
+	return NewServer().GoGoo()
+}
+
+// NewGoGoo starts a new fake server and returns it with a GoGoo connected to it.
+// The server should be closed by the caller.
+func NewGoGoo() (*Server, *gogoo.GoGoo) {
+	server := NewServer()
+	return server, server.GoGoo()
+}
+
+// GoGoo builds a GoGoo connected to the server
+func (server *Server) GoGoo() *gogoo.GoGoo {
+	endpoints := map[gogoo.Component]string{}
+	for _, component := range Components {
+		endpoints[component] = server.URL
+	}
+
+	g, err := gogoo.New(gogoo.AppContext{
+		ProjectId:   ProjectId,
+		Credentials: auth.None(),
+		Components:  Components,
+		HTTPClient:  server.Client(),
+		Endpoints:   endpoints,
+		Retry:       &RetryPolicy,
+	})
+	if err != nil {
+		panic(err)
+	}
+	manager := g.Compute.(*gce.GceManager)
+	manager.OperationPollInterval = PollInterval
+	manager.StatusPollInterval = PollInterval
+
+	return g
+}
+
+func (server *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	var (
+		result interface{}
+		err    *apiError
+	)
+
+	server.requests++
+
+	path := r.URL.Path
+	switch {
+	case len(server.requestFailures) > 0:
+		err = server.requestFailures[0]
+		server.requestFailures = server.requestFailures[1:]
+	case strings.HasPrefix(path, computePrefix) && server.servedRequests[r.URL.Query().Get("requestId")] != nil:
+		// The retried request of the same id gets the response of the applied one like compute engine
+		result = server.servedRequests[r.URL.Query().Get("requestId")]
+	case strings.HasPrefix(path, computePrefix):
+		result, err = server.serveCompute(r, strings.TrimPrefix(path, computePrefix))
+		if requestId := r.URL.Query().Get("requestId"); requestId != "" && err == nil {
+			server.servedRequests[requestId] = result
+		}
+	case strings.HasPrefix(path, sqlPrefix):
+		result, err = server.serveSql(r, strings.TrimPrefix(path, sqlPrefix))
+	case strings.HasPrefix(path, pubsubPrefix):
+		result, err = server.servePubsub(r, strings.TrimPrefix(path, pubsubPrefix))
+	case strings.HasPrefix(path, storagePrefix):
+		result, err = server.serveStorage(r, strings.TrimPrefix(path, storagePrefix))
+	case strings.HasPrefix(path, rpuPrefix):
+		result, err = server.serveRpu(r, strings.TrimPrefix(path, rpuPrefix))
+	case strings.HasPrefix(path, monitorPrefix):
+		result, err = server.serveMonitor(r, strings.TrimPrefix(path, monitorPrefix))
+	default:
+		err = notFound(path)
+	}
+
+	if err == nil && len(server.responseFailures) > 0 {
+		err = server.responseFailures[0]
+		server.responseFailures = server.responseFailures[1:]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		w.WriteHeader(err.Code)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error": err})
+		return
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// FailNextRequests makes the next n requests of any api respond the error of the
+// status and reason, e.g. 503 "backendError" or 429 "rateLimitExceeded"
+func (server *Server) FailNextRequests(n int, code int, reason string) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	for i := 0; i < n; i++ {
+		server.requestFailures = append(server.requestFailures,
+			newAPIError(code, reason, fmt.Sprintf("Injected failure: %s", reason)))
+	}
+}
+
+// FailNextResponses is FailNextRequests but the requests are served before the error is
+// responded, e.g. the instance is inserted though the caller receives 503 "backendError"
+func (server *Server) FailNextResponses(n int, code int, reason string) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	for i := 0; i < n; i++ {
+		server.responseFailures = append(server.responseFailures,
+			newAPIError(code, reason, fmt.Sprintf("Injected failure: %s", reason)))
+	}
+}
+
+// SetPageSize makes the lists respond at most n items per page unless `maxResults` is given
+func (server *Server) SetPageSize(n int) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	server.pageSize = n
+}
+
+// Requests returns the number of requests served
+func (server *Server) Requests() int {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	return server.requests
+}
+
+// apiError is the error body google apis respond
+type apiError struct {
+	Code    int           `json:"code"`
+	Message string        `json:"message"`
+	Errors  []errorDetail `json:"errors"`
+}
+
+type errorDetail struct {
+	Domain  string `json:"domain"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+func newAPIError(code int, reason, message string) *apiError {
+	return &apiError{
+		Code:    code,
+		Message: message,
+		Errors:  []errorDetail{{Domain: "global", Reason: reason, Message: message}},
+	}
+}
+
+func notFound(path string) *apiError {
+	return newAPIError(http.StatusNotFound, "notFound",
+		fmt.Sprintf("The resource '%s' was not found", path))
+}
+
+func alreadyExists(path string) *apiError {
+	return newAPIError(http.StatusConflict, "alreadyExists",
+		fmt.Sprintf("The resource '%s' already exists", path))
+}
+
+func badRequest(message string) *apiError {
+	return newAPIError(http.StatusBadRequest, "invalid", message)
+}
+
+// decode decodes the request body into a resource
+func decode(r *http.Request) (resource, *apiError) {
+	body := resource{}
+	if r.Body == nil || r.ContentLength == 0 {
+		return body, nil
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, badRequest(err.Error())
+	}
+
+	return body, nil
+}
+
+// nextId generates the sequential id of resources
+func (server *Server) nextId() string {
+	server.sequence++
+
+	return strconv.Itoa(server.sequence)
+}
+
+func now() string {
+	return time.Now().Format(time.RFC3339)
+}
+
+func (server *Server) collection(path string) map[string]resource {
+	c, ok := server.collections[path]
+	if !ok {
+		c = map[string]resource{}
+		server.collections[path] = c
+	}
+
+	return c
+}
+
+func (server *Server) get(collection, name string) (resource, *apiError) {
+	item, ok := server.collection(collection)[name]
+	if !ok {
+		return nil, notFound(collection + "/" + name)
+	}
+
+	return item, nil
+}
+
+func (server *Server) insert(collection, name string, item resource) *apiError {
+	c := server.collection(collection)
+	if _, ok := c[name]; ok {
+		return alreadyExists(collection + "/" + name)
+	}
+	c[name] = item
+
+	return nil
+}
+
+func (server *Server) delete(collection, name string) (resource, *apiError) {
+	c := server.collection(collection)
+	item, ok := c[name]
+	if !ok {
+		return nil, notFound(collection + "/" + name)
+	}
+	delete(c, name)
+
+	return item, nil
+}
+
+// list lists the resources of the collection matched by match ordered by name, it honors
+// `maxResults` and `pageToken` query parameters. All resources are listed if match is nil.
+func (server *Server) list(r *http.Request, collection string, match func(resource) bool) ([]resource, string) {
+	return paginate(r, server.sorted(collection, match), server.pageSize)
+}
+
+// sorted returns the resources of the collection matched by match ordered by name
+func (server *Server) sorted(collection string, match func(resource) bool) []resource {
+	c := server.collection(collection)
+
+	names := []string{}
+	for name := range c {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	items := []resource{}
+	for _, name := range names {
+		if match == nil || match(c[name]) {
+			items = append(items, c[name])
+		}
+	}
+
+	return items
+}
+
+// paginate cuts the page requested by `maxResults` and `pageToken`, `pageSize` is used
+// if `maxResults` is absent. The token of next page is the offset of its first item.
+func paginate(r *http.Request, items []resource, pageSize int) ([]resource, string) {
+	offset, _ := strconv.Atoi(r.URL.Query().Get("pageToken"))
+	if offset > len(items) {
+		offset = len(items)
+	}
+	items = items[offset:]
+
+	maxResults, _ := strconv.Atoi(r.URL.Query().Get("maxResults"))
+	if maxResults <= 0 {
+		maxResults = pageSize
+	}
+	if maxResults <= 0 || maxResults >= len(items) {
+		return items, ""
+	}
+
+	return items[:maxResults], strconv.Itoa(offset + maxResults)
+}
+
+// seed converts the api object into resource and stores it
+func (server *Server) seed(collection, name string, object interface{}) resource {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	item := toResource(object)
+	server.collection(collection)[name] = item
+
+	return item
+}
+
+// toResource converts the api object into its JSON object representation
+func toResource(object interface{}) resource {
+	b, err := json.Marshal(object)
+	if err != nil {
+		panic(err)
+	}
+
+	item := resource{}
+	if err := json.Unmarshal(b, &item); err != nil {
+		panic(err)
+	}
+
+	return item
+}
+
+// merge copies the fields of patch into item
+func merge(item, patch resource) {
+	for k, v := range patch {
+		item[k] = v
+	}
+}
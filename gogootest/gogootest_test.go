@@ -0,0 +1,89 @@
+package gogootest_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/browny/gogoo/gce"
+	"github.com/browny/gogoo/gerror"
+	"github.com/browny/gogoo/gogootest"
+	"github.com/browny/gogoo/retry"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	compute "google.golang.org/api/compute/v1"
+	sql "google.golang.org/api/sqladmin/v1beta4"
+)
+
+const testedZone = "asia-east1-b"
+
+func TestGoGoo(t *testing.T) {
+	server, g := gogootest.NewGoGoo()
+	defer server.Close()
+
+	// Datastore is not faked
+	assert.Nil(t, g.Store)
+	assert.NotNil(t, g.Compute)
+
+	manager := g.Compute.(*gce.GceManager)
+	assert.Equal(t, gogootest.PollInterval, manager.OperationPollInterval)
+	assert.Equal(t, gogootest.PollInterval, manager.StatusPollInterval)
+}
+
+func TestRetry(t *testing.T) {
+	server, g := gogootest.NewGoGoo()
+	defer server.Close()
+
+	server.AddImage(&compute.Image{Name: "image-test"})
+
+	// The rate limited insert is rejected before it is applied, so it is retried
+	server.FailNextResponses(1, http.StatusTooManyRequests, "rateLimitExceeded")
+	assert.Nil(t, g.NewVm(gogootest.ProjectId, testedZone, &compute.Instance{Name: "instance-test"}))
+	_, err := g.GetVm(gogootest.ProjectId, testedZone, "instance-test")
+	assert.Nil(t, err)
+
+	// The insert applied before it fails is retried by its requestId without alreadyExists
+	server.FailNextResponses(1, http.StatusServiceUnavailable, "backendError")
+	requests := server.Requests()
+	assert.Nil(t, g.NewDisk(gogootest.ProjectId, testedZone, "disk-test", "", 10))
+	disks, _ := g.ListDisks(gogootest.ProjectId, testedZone)
+	assert.Equal(t, 1, len(disks.Items))
+	assert.True(t, server.Requests()-requests > 2)
+
+	// The other POST applied before it fails is not retried
+	server.FailNextResponses(1, http.StatusServiceUnavailable, "backendError")
+	requests = server.Requests()
+	_, err = g.StopVm(gogootest.ProjectId, testedZone, "instance-test", nil)
+	assert.True(t, gerror.IsRetryable(err))
+	assert.Equal(t, 1, server.Requests()-requests)
+
+	// The patch of the whole acl list is idempotent
+	server.AddDatabase(&sql.DatabaseInstance{Name: "db-test", Settings: &sql.Settings{IpConfiguration: &sql.IpConfiguration{}}})
+	server.FailNextResponses(1, http.StatusServiceUnavailable, "backendError")
+	_, err = g.PatchAclEntriesOfDatabase(gogootest.ProjectId, "db-test", []*sql.AclEntry{{Name: "office", Value: "1.2.3.4"}})
+	assert.Nil(t, err)
+
+	server.FailNextRequests(2, http.StatusTooManyRequests, "rateLimitExceeded")
+	requests = server.Requests()
+	images, err := g.ListImages(gogootest.ProjectId)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(images.Items))
+	assert.Equal(t, 3, server.Requests()-requests)
+
+	// The policy is overridden by the context of the call
+	server.FailNextRequests(1, http.StatusServiceUnavailable, "backendError")
+	ctx := retry.WithPolicy(context.Background(), retry.NoRetry)
+	_, err = g.ListImagesContext(ctx, gogootest.ProjectId)
+	assert.True(t, gerror.IsRetryable(err))
+
+	// The error not retryable is returned at once
+	requests = server.Requests()
+	_, err = g.GetVm(gogootest.ProjectId, testedZone, "instance-not-existed")
+	assert.True(t, gerror.IsNotFound(err))
+	assert.Equal(t, 1, server.Requests()-requests)
+
+	// The attempts are used up
+	server.FailNextRequests(gogootest.RetryPolicy.MaxAttempts, http.StatusInternalServerError, "internalError")
+	_, err = g.GetDatabase(gogootest.ProjectId, "db-not-existed")
+	assert.Equal(t, http.StatusInternalServerError, err.(*gerror.Error).StatusCode)
+}
@@ -0,0 +1,280 @@
+package gogootest
+
+import (
+	"net/http"
+	"strings"
+
+	sql "google.golang.org/api/sqladmin/v1beta4"
+	storage "google.golang.org/api/storage/v1"
+)
+
+const (
+	sqlPrefix     = "/sql/v1beta4/projects/"
+	pubsubPrefix  = "/v1/projects/"
+	storagePrefix = "/storage/v1/b"
+	rpuPrefix     = "/replicapoolupdater/v1beta1/projects/"
+	monitorPrefix = "/cloudmonitoring/v2beta2/projects/"
+
+	cpuUtilizationMetric = "compute.googleapis.com/instance/cpu/utilization"
+	instanceNameLabel    = "compute.googleapis.com/instance_name"
+)
+
+func methodNotAllowed(r *http.Request) *apiError {
+	return newAPIError(http.StatusMethodNotAllowed, "badRequest", r.Method+" "+r.URL.Path)
+}
+
+// serveSql serves {project}/instances/{instance} of sqladmin api
+func (server *Server) serveSql(r *http.Request, path string) (interface{}, *apiError) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) < 2 || segments[1] != "instances" {
+		return nil, notFound(path)
+	}
+	collection := "sql/" + segments[0] + "/instances"
+
+	switch {
+	case len(segments) == 2 && r.Method == "GET":
+		items, nextPageToken := server.list(r, collection, nil)
+		return resource{"kind": "sql#instancesList", "items": items, "nextPageToken": nextPageToken}, nil
+	case len(segments) == 3 && r.Method == "GET":
+		return server.get(collection, segments[2])
+	case len(segments) == 3 && r.Method == "PATCH":
+		item, err := server.get(collection, segments[2])
+		if err != nil {
+			return nil, err
+		}
+		body, err := decode(r)
+		if err != nil {
+			return nil, err
+		}
+		merge(item, body)
+		return server.sqlOperation(segments[0], item, "UPDATE"), nil
+	}
+
+	return nil, methodNotAllowed(r)
+}
+
+func (server *Server) sqlOperation(project string, item resource, operationType string) resource {
+	name := server.nextId()
+	op := resource{
+		"kind":          "sql#operation",
+		"name":          name,
+		"operationType": operationType,
+		"status":        "DONE",
+		"targetId":      item["name"],
+		"targetProject": project,
+		"targetLink":    item["selfLink"],
+		"insertTime":    now(),
+	}
+	server.collection("sql/" + project + "/operations")[name] = op
+
+	return op
+}
+
+// servePubsub serves v1/projects/{project}/topics/{topic} of pub/sub api
+func (server *Server) servePubsub(r *http.Request, path string) (interface{}, *apiError) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) < 2 || segments[1] != "topics" {
+		return nil, notFound(path)
+	}
+	collection := "pubsub/" + segments[0] + "/topics"
+
+	switch {
+	case len(segments) == 2 && r.Method == "GET":
+		items, nextPageToken := server.list(r, collection, nil)
+		return resource{"topics": items, "nextPageToken": nextPageToken}, nil
+	case len(segments) == 3 && r.Method == "GET":
+		return server.get(collection, segments[2])
+	case len(segments) == 3 && r.Method == "PUT":
+		topic := resource{"name": "projects/" + segments[0] + "/topics/" + segments[2]}
+		if err := server.insert(collection, segments[2], topic); err != nil {
+			return nil, err
+		}
+		return topic, nil
+	case len(segments) == 3 && r.Method == "DELETE":
+		if _, err := server.delete(collection, segments[2]); err != nil {
+			return nil, err
+		}
+		return resource{}, nil
+	}
+
+	return nil, methodNotAllowed(r)
+}
+
+// serveStorage serves b/{bucket} of storage api. Buckets of all projects are listed together.
+func (server *Server) serveStorage(r *http.Request, path string) (interface{}, *apiError) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	collection := "storage/b"
+
+	switch {
+	case path == "" && r.Method == "GET":
+		items, nextPageToken := server.list(r, collection, nil)
+		return resource{"kind": "storage#buckets", "items": items, "nextPageToken": nextPageToken}, nil
+	case path == "" && r.Method == "POST":
+		body, err := decode(r)
+		if err != nil {
+			return nil, err
+		}
+		name, _ := body["name"].(string)
+		if name == "" {
+			return nil, badRequest("name is required")
+		}
+		server.initBucket(body)
+		if err := server.insert(collection, name, body); err != nil {
+			return nil, err
+		}
+		return body, nil
+	case len(segments) == 1 && r.Method == "GET":
+		return server.get(collection, segments[0])
+	case len(segments) == 1 && r.Method == "DELETE":
+		if _, err := server.delete(collection, segments[0]); err != nil {
+			return nil, err
+		}
+		return resource{}, nil
+	}
+
+	return nil, methodNotAllowed(r)
+}
+
+func (server *Server) initBucket(item resource) {
+	item["kind"] = "storage#bucket"
+	item["id"] = item["name"]
+	item["timeCreated"] = now()
+	item["selfLink"] = "https://www.googleapis.com/storage/v1/b/" + item["name"].(string)
+}
+
+// serveRpu serves {project}/zones/{zone}/rollingUpdates/{rollingUpdate}/{action} of replicapoolupdater api
+func (server *Server) serveRpu(r *http.Request, path string) (interface{}, *apiError) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) < 4 || segments[1] != "zones" || segments[3] != "rollingUpdates" {
+		return nil, notFound(path)
+	}
+	project, zone := segments[0], segments[2]
+	collection := "rpu/" + project + "/zones/" + zone + "/rollingUpdates"
+	link := "https://www.googleapis.com/replicapoolupdater/v1beta1/projects/" + strings.Join(segments[:4], "/")
+
+	switch {
+	case len(segments) == 4 && r.Method == "GET":
+		items, nextPageToken := server.list(r, collection, nil)
+		return resource{"kind": "replicapoolupdater#rollingUpdateList", "items": items, "nextPageToken": nextPageToken}, nil
+	case len(segments) == 4 && r.Method == "POST":
+		body, err := decode(r)
+		if err != nil {
+			return nil, err
+		}
+		id := server.nextId()
+		body["kind"] = "replicapoolupdater#rollingUpdate"
+		body["id"] = id
+		body["status"] = "ROLLING_FORWARD"
+		body["creationTimestamp"] = now()
+		body["selfLink"] = link + "/" + id
+		server.insert(collection, id, body)
+		return server.rpuOperation(project, zone, body, "insert"), nil
+	case len(segments) == 5 && r.Method == "GET":
+		return server.get(collection, segments[4])
+	case len(segments) == 6 && r.Method == "POST":
+		item, err := server.get(collection, segments[4])
+		if err != nil {
+			return nil, err
+		}
+		switch segments[5] {
+		case "rollback":
+			item["status"] = "ROLLED_BACK"
+		case "cancel":
+			item["status"] = "CANCELLED"
+		case "pause":
+			item["status"] = "PAUSED"
+		case "resume":
+			item["status"] = "ROLLING_FORWARD"
+		default:
+			return nil, notFound(path)
+		}
+		return server.rpuOperation(project, zone, item, segments[5]), nil
+	}
+
+	return nil, methodNotAllowed(r)
+}
+
+func (server *Server) rpuOperation(project, zone string, item resource, operationType string) resource {
+	id := server.nextId()
+	op := resource{
+		"kind":          "replicapoolupdater#operation",
+		"id":            id,
+		"name":          "operation-" + id,
+		"operationType": operationType,
+		"status":        "DONE",
+		"progress":      100,
+		"targetLink":    item["selfLink"],
+		"zone":          computeLink + project + "/zones/" + zone,
+		"insertTime":    now(),
+	}
+	server.collection("rpu/" + project + "/zones/" + zone + "/operations")[op["name"].(string)] = op
+
+	return op
+}
+
+// serveMonitor serves {project}/timeseries/{metric} of cloud monitoring api.
+// Only the cpu utilization of instances set by SetCpuUtilization is available.
+func (server *Server) serveMonitor(r *http.Request, path string) (interface{}, *apiError) {
+	segments := strings.SplitN(strings.Trim(path, "/"), "/", 3)
+	if len(segments) < 3 || segments[1] != "timeseries" || r.Method != "GET" {
+		return nil, notFound(path)
+	}
+	project, metric := segments[0], segments[2]
+
+	timeseries := []resource{}
+	for _, label := range r.URL.Query()["labels"] {
+		pair := strings.SplitN(label, "==", 2)
+		if metric != cpuUtilizationMetric || len(pair) != 2 || pair[0] != instanceNameLabel {
+			continue
+		}
+		item, ok := server.collection("monitor/" + project + "/cpu")[pair[1]]
+		if !ok {
+			continue
+		}
+		timeseries = append(timeseries, resource{
+			"timeseriesDesc": resource{
+				"project": project,
+				"metric":  metric,
+				"labels":  resource{instanceNameLabel: pair[1]},
+			},
+			"points": []resource{item},
+		})
+	}
+
+	return resource{
+		"kind":       "cloudmonitoring#listTimeseriesResponse",
+		"youngest":   r.URL.Query().Get("youngest"),
+		"timeseries": timeseries,
+	}, nil
+}
+
+// AddDatabase adds a cloud sql instance into the project
+func (server *Server) AddDatabase(instance *sql.DatabaseInstance) {
+	instance.SelfLink = "https://www.googleapis.com/sql/v1beta4/projects/" + ProjectId + "/instances/" + instance.Name
+	server.seed("sql/"+ProjectId+"/instances", instance.Name, instance)
+}
+
+// AddTopic adds a pub/sub topic into the project
+func (server *Server) AddTopic(topic string) {
+	server.seed("pubsub/"+ProjectId+"/topics", topic, resource{
+		"name": "projects/" + ProjectId + "/topics/" + topic,
+	})
+}
+
+// AddBucket adds a storage bucket
+func (server *Server) AddBucket(bucket *storage.Bucket) {
+	item := server.seed("storage/b", bucket.Name, bucket)
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.initBucket(item)
+}
+
+// SetCpuUtilization sets the average cpu utilization reported by cloud monitoring for the instance
+func (server *Server) SetCpuUtilization(instanceName string, utilization float64) {
+	server.seed("monitor/"+ProjectId+"/cpu", instanceName, resource{
+		"start":       now(),
+		"end":         now(),
+		"doubleValue": utilization,
+	})
+}
@@ -2,88 +2,363 @@
 package gogoo
 
 import (
-	"os"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
 
+	"github.com/browny/gogoo/auth"
 	"github.com/browny/gogoo/cloudsql"
 	"github.com/browny/gogoo/gce"
 	"github.com/browny/gogoo/gcm"
 	"github.com/browny/gogoo/gds"
 	"github.com/browny/gogoo/pubsub"
 	"github.com/browny/gogoo/replicapoolupdater"
+	"github.com/browny/gogoo/retry"
 	"github.com/browny/gogoo/storage"
+	"github.com/browny/gogoo/utility"
 
 	"github.com/facebookgo/inject"
+	"golang.org/x/net/context"
+	"golang.org/x/oauth2"
+	"google.golang.org/cloud"
 )
 
-var gogoo GoGoo
-var gceManager gce.GceManager
-var gdsManager gds.GdsManager
-var gcmManager gcm.GcmManager
-var cloudSqlManager cloudsql.CloudSqlManager
-var rpuManager replicapoolupdater.RpuManager
-var pbsbManager pubsub.PbsbManager
-var storageManager storage.StorageManager
+// Component identifies one of the managers held by GoGoo
+type Component string
+
+const (
+	Gce      Component = "gce"
+	Gds      Component = "gds"
+	Gcm      Component = "gcm"
+	CloudSql Component = "cloudsql"
+	Rpu      Component = "replicapoolupdater"
+	Pbsb     Component = "pubsub"
+	Storage  Component = "storage"
+)
+
+// AllComponents lists every component GoGoo is able to build
+var AllComponents = []Component{Gce, Gds, Gcm, CloudSql, Rpu, Pbsb, Storage}
+
+// scopesOfComponent lists the oauth2 scopes required by each component
+var scopesOfComponent = map[Component][]string{
+	Gce:      gce.Scopes,
+	Gds:      gds.Scopes,
+	Gcm:      gcm.Scopes,
+	CloudSql: cloudsql.Scopes,
+	Rpu:      replicapoolupdater.Scopes,
+	Pbsb:     pubsub.Scopes,
+	Storage:  storage.Scopes,
+}
 
 // Input parameter object to initialize GoGoo
 type AppContext struct {
 	ServiceAccount      string
 	KeyOfServiceAccount []byte
 	ProjectId           string
+
+	// Credentials authorizes all managers by one shared token source.
+	// ServiceAccount and KeyOfServiceAccount are used if it is nil.
+	Credentials auth.Provider
+
+	// Components lists the managers to build. All managers are built if it is empty.
+	Components []Component
+
+	// HTTPClient is the base client of all managers, the authorization is added on top
+	// of its transport. http.DefaultClient is used if it is nil.
+	HTTPClient *http.Client
+
+	// Transport wraps the transport of HTTPClient, e.g. to record or stub requests.
+	// The wrapped transport receives the requests after they are authorized.
+	Transport func(http.RoundTripper) http.RoundTripper
+
+	// Endpoints overrides the root url of the component's api, e.g. an emulator,
+	// a local fake or a corporate proxy. The api path is kept, so
+	// "http://localhost:8080" turns compute api into "http://localhost:8080/compute/v1/projects/".
+	Endpoints map[Component]string
+
+	// Retry is the policy to retry the transient failures of all api calls,
+	// retry.DefaultPolicy is used if it is nil, and retry.NoRetry disables it.
+	// It is overridden for a single call by the context attached with retry.WithPolicy.
+	Retry *retry.Policy
 }
 
-// GoGoo acts as the handler to access different subpackages
+// BuildError reports the components which fail to be built by New
+type BuildError struct {
+	Errors map[Component]error
+}
+
+func (e *BuildError) Error() string {
+	components := []string{}
+	for component := range e.Errors {
+		components = append(components, string(component))
+	}
+	sort.Strings(components)
+
+	messages := []string{}
+	for _, component := range components {
+		messages = append(messages,
+			fmt.Sprintf("%s: %s", component, e.Errors[Component(component)].Error()))
+	}
+
+	return fmt.Sprintf("GoGoo build fails: %s", strings.Join(messages, "; "))
+}
+
+// GoGoo acts as the handler to access different subpackages.
+// Managers are exposed by their interfaces, so they can be replaced by the mocks
+// in the `mocks` directory of each subpackage.
+// Managers of the components not requested in AppContext are nil.
+// Every GoGoo owns its managers and the underlying clients, so different
+// GoGoo objects never share state and can be used concurrently.
 type GoGoo struct {
-	*gce.GceManager                `inject:""`
-	*gds.GdsManager                `inject:""`
-	*gcm.GcmManager                `inject:""`
-	*cloudsql.CloudSqlManager      `inject:""`
-	*replicapoolupdater.RpuManager `inject:""`
-	*pubsub.PbsbManager            `inject:""`
-	*storage.StorageManager        `inject:""`
+	gce.Compute
+	gds.Store
+	gcm.Monitor
+	cloudsql.Admin
+	replicapoolupdater.Updater
+	pubsub.Topics
+	storage.Buckets
 }
 
 // Used to create a new GoGoo object.
-func New(ctx AppContext) *GoGoo {
-	buildDependencyGraph(ctx)
+// All construction errors of the requested components are collected into a *BuildError.
+func New(ctx AppContext) (*GoGoo, error) {
+	return buildDependencyGraph(ctx)
+}
+
+// credentials returns the credential provider of the context
+func (ctx AppContext) credentials() auth.Provider {
+	if ctx.Credentials != nil {
+		return ctx.Credentials
+	}
 
-	return &gogoo
+	return auth.PemKey(ctx.ServiceAccount, ctx.KeyOfServiceAccount)
+}
+
+// httpClient builds the client shared by all managers
+func (ctx AppContext) httpClient(ts oauth2.TokenSource) *http.Client {
+	client := http.Client{}
+	if ctx.HTTPClient != nil {
+		client = *ctx.HTTPClient
+	}
+
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if ctx.Transport != nil {
+		transport = ctx.Transport(transport)
+	}
+	if ts != nil {
+		transport = &oauth2.Transport{Source: ts, Base: transport}
+	}
+	// Each attempt is authorized again, so the token expired during backoff is refreshed
+	client.Transport = &retry.Transport{Base: transport, Policy: ctx.Retry}
+
+	return &client
+}
+
+// basePath replaces the root url of the default base path by the endpoint of the component
+func (ctx AppContext) basePath(component Component, defaultBasePath string) (string, error) {
+	endpoint, ok := ctx.Endpoints[component]
+	if !ok || endpoint == "" {
+		return defaultBasePath, nil
+	}
+
+	root, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	if root.Scheme == "" || root.Host == "" {
+		return "", fmt.Errorf("endpoint should be an absolute url: %s", endpoint)
+	}
+
+	path, err := url.Parse(defaultBasePath)
+	if err != nil {
+		return "", err
+	}
+	root.Path = strings.TrimSuffix(root.Path, "/") + path.Path
+
+	return root.String(), nil
+}
+
+// requested returns the set of components to build
+func (ctx AppContext) requested() map[Component]bool {
+	components := ctx.Components
+	if len(components) == 0 {
+		components = AllComponents
+	}
+
+	result := map[Component]bool{}
+	for _, component := range components {
+		result[component] = true
+	}
+
+	return result
+}
+
+func isKnownComponent(component Component) bool {
+	for _, c := range AllComponents {
+		if c == component {
+			return true
+		}
+	}
+
+	return false
 }
 
 // Construct dependency graph
-func buildDependencyGraph(ctx AppContext) {
-	computeService, _ := gce.BuildGceService(ctx.ServiceAccount, ctx.KeyOfServiceAccount)
-	_, client, _ := gds.BuildGdsContext(
-		ctx.ServiceAccount,
-		ctx.KeyOfServiceAccount,
-		ctx.ProjectId)
-	cloudmonitorService, _ := gcm.BuildCloudMonitorService(ctx.ServiceAccount, ctx.KeyOfServiceAccount)
-	sqlService, _ := cloudsql.BuildCloudSqlService(ctx.ServiceAccount, ctx.KeyOfServiceAccount)
-	rpuService, _ := replicapoolupdater.BuildRpuService(ctx.ServiceAccount, ctx.KeyOfServiceAccount)
-	pbsbService, _ := pubsub.BuildPbsbService(ctx.ServiceAccount, ctx.KeyOfServiceAccount)
-	storageService, _ := storage.BuildStorageService(ctx.ServiceAccount, ctx.KeyOfServiceAccount)
+func buildDependencyGraph(ctx AppContext) (*GoGoo, error) {
+	requested := ctx.requested()
+	buildErr := &BuildError{Errors: map[Component]error{}}
 
-	var g inject.Graph
-	err := g.Provide(
-		&inject.Object{Value: client},
-		&inject.Object{Value: computeService},
-		&inject.Object{Value: cloudmonitorService},
-		&inject.Object{Value: sqlService},
-		&inject.Object{Value: rpuService},
-		&inject.Object{Value: pbsbService},
-		&inject.Object{Value: storageService},
-		&inject.Object{Value: &gdsManager},
-		&inject.Object{Value: &gceManager},
-		&inject.Object{Value: &gcmManager},
-		&inject.Object{Value: &cloudSqlManager},
-		&inject.Object{Value: &rpuManager},
-		&inject.Object{Value: &pbsbManager},
-		&inject.Object{Value: &storageManager},
-		&inject.Object{Value: &gogoo},
-	)
+	for component := range requested {
+		switch {
+		case !isKnownComponent(component):
+			buildErr.Errors[component] = fmt.Errorf("unknown component")
+		case ctx.Credentials == nil && (ctx.ServiceAccount == "" || len(ctx.KeyOfServiceAccount) == 0):
+			buildErr.Errors[component] = fmt.Errorf("service account or its key is empty")
+		case component == Gds && ctx.ProjectId == "":
+			buildErr.Errors[component] = fmt.Errorf("project id is required")
+		}
+	}
+	if len(buildErr.Errors) > 0 {
+		return nil, buildErr
+	}
+
+	// One token source is shared by all managers, so a token is fetched once for all of them
+	scopes := []string{}
+	for component := range requested {
+		for _, scope := range scopesOfComponent[component] {
+			if !utility.InStringSlice(scopes, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	sort.Strings(scopes)
+
+	ts, err := ctx.credentials().TokenSource(context.Background(), scopes...)
 	if err != nil {
-		os.Exit(1)
+		for component := range requested {
+			buildErr.Errors[component] = err
+		}
+		return nil, buildErr
+	}
+	if ts != nil {
+		ts = oauth2.ReuseTokenSource(nil, ts)
+	}
+	client := ctx.httpClient(ts)
+
+	var gceManager gce.GceManager
+	var gdsManager gds.GdsManager
+	var gcmManager gcm.GcmManager
+	var cloudSqlManager cloudsql.CloudSqlManager
+	var rpuManager replicapoolupdater.RpuManager
+	var pbsbManager pubsub.PbsbManager
+	var storageManager storage.StorageManager
+
+	objects := []*inject.Object{}
+	provide := func(component Component, service interface{}, manager interface{}, err error) {
+		if err != nil {
+			buildErr.Errors[component] = err
+			return
+		}
+		objects = append(objects, &inject.Object{Value: service}, &inject.Object{Value: manager})
+	}
+	// rebase points the base path of the built service to the endpoint of the component
+	rebase := func(component Component, basePath *string) (err error) {
+		*basePath, err = ctx.basePath(component, *basePath)
+		return err
+	}
+
+	if requested[Gce] {
+		computeService, err := gce.BuildGceServiceWithClient(client)
+		if err == nil {
+			err = rebase(Gce, &computeService.BasePath)
+		}
+		provide(Gce, computeService, &gceManager, err)
+	}
+	if requested[Gds] {
+		endpoint, err := ctx.basePath(Gds, gds.BasePath)
+		var datastoreClient interface{}
+		if err == nil {
+			_, datastoreClient, err = gds.BuildGdsContextWithClient(
+				client, ctx.ProjectId, cloud.WithEndpoint(endpoint))
+		}
+		provide(Gds, datastoreClient, &gdsManager, err)
+	}
+	if requested[Gcm] {
+		cloudmonitorService, err := gcm.BuildCloudMonitorServiceWithClient(client)
+		if err == nil {
+			err = rebase(Gcm, &cloudmonitorService.BasePath)
+		}
+		provide(Gcm, cloudmonitorService, &gcmManager, err)
+	}
+	if requested[CloudSql] {
+		sqlService, err := cloudsql.BuildCloudSqlServiceWithClient(client)
+		if err == nil {
+			err = rebase(CloudSql, &sqlService.BasePath)
+		}
+		provide(CloudSql, sqlService, &cloudSqlManager, err)
+	}
+	if requested[Rpu] {
+		rpuService, err := replicapoolupdater.BuildRpuServiceWithClient(client)
+		if err == nil {
+			err = rebase(Rpu, &rpuService.BasePath)
+		}
+		provide(Rpu, rpuService, &rpuManager, err)
+	}
+	if requested[Pbsb] {
+		pbsbService, err := pubsub.BuildPbsbServiceWithClient(client)
+		if err == nil {
+			err = rebase(Pbsb, &pbsbService.BasePath)
+		}
+		provide(Pbsb, pbsbService, &pbsbManager, err)
+	}
+	if requested[Storage] {
+		storageService, err := storage.BuildStorageServiceWithClient(client)
+		if err == nil {
+			err = rebase(Storage, &storageService.BasePath)
+		}
+		provide(Storage, storageService, &storageManager, err)
+	}
+	if len(buildErr.Errors) > 0 {
+		return nil, buildErr
+	}
+
+	var g inject.Graph
+	if err := g.Provide(objects...); err != nil {
+		return nil, err
 	}
 	if err := g.Populate(); err != nil {
-		os.Exit(1)
+		return nil, err
+	}
+
+	gogoo := &GoGoo{}
+	if requested[Gce] {
+		gogoo.Compute = &gceManager
+	}
+	if requested[Gds] {
+		gogoo.Store = &gdsManager
+	}
+	if requested[Gcm] {
+		gogoo.Monitor = &gcmManager
 	}
+	if requested[CloudSql] {
+		gogoo.Admin = &cloudSqlManager
+	}
+	if requested[Rpu] {
+		gogoo.Updater = &rpuManager
+	}
+	if requested[Pbsb] {
+		pbsbManager.Setup()
+		gogoo.Topics = &pbsbManager
+	}
+	if requested[Storage] {
+		storageManager.Setup()
+		gogoo.Buckets = &storageManager
+	}
+
+	return gogoo, nil
 }
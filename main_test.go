@@ -0,0 +1,232 @@
+package gogoo_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/browny/gogoo"
+	"github.com/browny/gogoo/auth"
+	"github.com/browny/gogoo/cloudsql"
+	"github.com/browny/gogoo/gce"
+	gcemocks "github.com/browny/gogoo/gce/mocks"
+	"github.com/browny/gogoo/gcm"
+	"github.com/browny/gogoo/gds"
+	"github.com/browny/gogoo/pubsub"
+	"github.com/browny/gogoo/replicapoolupdater"
+	"github.com/browny/gogoo/storage"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+	compute "google.golang.org/api/compute/v1"
+)
+
+var testedKey = func() []byte {
+	privateKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	})
+}()
+
+func TestNewWithSelectedComponents(t *testing.T) {
+	g, err := gogoo.New(gogoo.AppContext{
+		ServiceAccount:      "ooxx@developer.gserviceaccount.com",
+		KeyOfServiceAccount: testedKey,
+		Components:          []gogoo.Component{gogoo.Gce},
+	})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, g.Compute)
+	assert.NotNil(t, g.Compute.(*gce.GceManager).Service)
+	assert.Nil(t, g.Store)
+	assert.Nil(t, g.Admin)
+}
+
+func TestNewReportsFailedComponents(t *testing.T) {
+	_, err := gogoo.New(gogoo.AppContext{
+		ServiceAccount:      "ooxx@developer.gserviceaccount.com",
+		KeyOfServiceAccount: testedKey,
+		Components:          []gogoo.Component{gogoo.Gce, gogoo.Gds, "unknown"},
+	})
+
+	buildErr, ok := err.(*gogoo.BuildError)
+	assert.True(t, ok)
+	assert.Equal(t, 2, len(buildErr.Errors))
+	assert.NotNil(t, buildErr.Errors[gogoo.Gds])
+	assert.NotNil(t, buildErr.Errors["unknown"])
+	assert.Contains(t, err.Error(), "gds: project id is required")
+}
+
+func TestNewWithoutCredentials(t *testing.T) {
+	g, err := gogoo.New(gogoo.AppContext{ProjectId: "project"})
+
+	assert.Nil(t, g)
+	assert.Equal(t, len(gogoo.AllComponents), len(err.(*gogoo.BuildError).Errors))
+}
+
+func TestNewBuildsIsolatedInstances(t *testing.T) {
+	appContext := func(account string) gogoo.AppContext {
+		return gogoo.AppContext{
+			ServiceAccount:      account,
+			KeyOfServiceAccount: testedKey,
+			ProjectId:           "project",
+		}
+	}
+
+	results := make(chan *gogoo.GoGoo, 2)
+	for _, account := range []string{"a@developer.gserviceaccount.com", "b@developer.gserviceaccount.com"} {
+		go func(account string) {
+			g, _ := gogoo.New(appContext(account))
+			results <- g
+		}(account)
+	}
+	first, second := <-results, <-results
+
+	assert.NotNil(t, first)
+	assert.NotNil(t, second)
+	assert.True(t, first.Compute != second.Compute)
+	assert.True(t, first.Compute.(*gce.GceManager).Service != second.Compute.(*gce.GceManager).Service)
+	assert.True(t, first.Store.(*gds.GdsManager).Client != second.Store.(*gds.GdsManager).Client)
+	assert.True(t, first.Buckets.(*storage.StorageManager).Service != second.Buckets.(*storage.StorageManager).Service)
+}
+
+func TestNewWithBadKey(t *testing.T) {
+	_, err := gogoo.New(gogoo.AppContext{
+		ServiceAccount:      "ooxx@developer.gserviceaccount.com",
+		KeyOfServiceAccount: []byte("bad key"),
+		Components:          []gogoo.Component{gogoo.Gce, gogoo.Storage},
+	})
+
+	buildErr := err.(*gogoo.BuildError)
+	assert.Equal(t, 2, len(buildErr.Errors))
+	assert.Contains(t, err.Error(), "gce: private key is not PEM encoded")
+}
+
+func TestNewWithCredentials(t *testing.T) {
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"})
+
+	g, err := gogoo.New(gogoo.AppContext{
+		Credentials: auth.StaticTokenSource(ts),
+		ProjectId:   "project",
+	})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, g.Compute)
+	assert.NotNil(t, g.Store)
+	assert.NotNil(t, g.Buckets)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestNewWithEndpointsAndTransport(t *testing.T) {
+	requests := []*http.Request{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name": "instance-test", "status": "RUNNING"}`))
+	}))
+	defer server.Close()
+
+	wrapped := 0
+	g, err := gogoo.New(gogoo.AppContext{
+		Credentials: auth.StaticTokenSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"})),
+		Components:  []gogoo.Component{gogoo.Gce},
+		Endpoints:   map[gogoo.Component]string{gogoo.Gce: server.URL},
+		Transport: func(base http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				wrapped++
+				return base.RoundTrip(req)
+			})
+		},
+	})
+	assert.Nil(t, err)
+
+	vm, err := g.GetVm("project", "zone", "instance-test")
+	assert.Nil(t, err)
+	assert.Equal(t, "RUNNING", vm.Status)
+	assert.Equal(t, 1, wrapped)
+	assert.Equal(t, "/compute/v1/projects/project/zones/zone/instances/instance-test", requests[0].URL.Path)
+	assert.Equal(t, "Bearer token", requests[0].Header.Get("Authorization"))
+}
+
+func TestNewWithoutAuthorization(t *testing.T) {
+	var authorization []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header["Authorization"]
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name": "instance-test"}`))
+	}))
+	defer server.Close()
+
+	g, err := gogoo.New(gogoo.AppContext{
+		Credentials: auth.None(),
+		HTTPClient:  server.Client(),
+		Components:  []gogoo.Component{gogoo.Gce},
+		Endpoints:   map[gogoo.Component]string{gogoo.Gce: server.URL + "/proxy/"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, server.URL+"/proxy/compute/v1/projects/", g.Compute.(*gce.GceManager).Service.BasePath)
+
+	g.Compute.(*gce.GceManager).Service.BasePath = server.URL + "/compute/v1/projects/"
+	_, err = g.GetVm("project", "zone", "instance-test")
+	assert.Nil(t, err)
+	assert.Nil(t, authorization)
+}
+
+func TestNewWithBadEndpoint(t *testing.T) {
+	_, err := gogoo.New(gogoo.AppContext{
+		Credentials: auth.None(),
+		Components:  []gogoo.Component{gogoo.Gce, gogoo.Storage},
+		Endpoints:   map[gogoo.Component]string{gogoo.Storage: "localhost"},
+	})
+
+	buildErr := err.(*gogoo.BuildError)
+	assert.Equal(t, 1, len(buildErr.Errors))
+	assert.NotNil(t, buildErr.Errors[gogoo.Storage])
+}
+
+func TestInterfacesCoverManagers(t *testing.T) {
+	pairs := []struct {
+		manager interface{}
+		iface   reflect.Type
+	}{
+		{&gce.GceManager{}, reflect.TypeOf((*gce.Compute)(nil)).Elem()},
+		{&gds.GdsManager{}, reflect.TypeOf((*gds.Store)(nil)).Elem()},
+		{&gcm.GcmManager{}, reflect.TypeOf((*gcm.Monitor)(nil)).Elem()},
+		{&cloudsql.CloudSqlManager{}, reflect.TypeOf((*cloudsql.Admin)(nil)).Elem()},
+		{&replicapoolupdater.RpuManager{}, reflect.TypeOf((*replicapoolupdater.Updater)(nil)).Elem()},
+		{&pubsub.PbsbManager{}, reflect.TypeOf((*pubsub.Topics)(nil)).Elem()},
+		{&storage.StorageManager{}, reflect.TypeOf((*storage.Buckets)(nil)).Elem()},
+	}
+
+	for _, pair := range pairs {
+		managerType := reflect.TypeOf(pair.manager)
+		for i := 0; i < managerType.NumMethod(); i++ {
+			method := managerType.Method(i)
+			_, ok := pair.iface.MethodByName(method.Name)
+			assert.True(t, ok, "%s.%s is missing in %s", managerType, method.Name, pair.iface)
+		}
+	}
+}
+
+func TestGoGooWithMocks(t *testing.T) {
+	mockedCompute := &gcemocks.Compute{}
+	mockedCompute.On("GetVm", "project", "zone", "instance-test").
+		Return(&compute.Instance{Name: "instance-test"}, nil)
+
+	g := &gogoo.GoGoo{Compute: mockedCompute}
+	vm, err := g.GetVm("project", "zone", "instance-test")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "instance-test", vm.Name)
+	mockedCompute.AssertExpectations(t)
+}
@@ -4,33 +4,52 @@
 package pubsub
 
 import (
-	"log"
+	"net/http"
 
+	"github.com/browny/gogoo/auth"
+	"github.com/browny/gogoo/gerror"
+
+	"golang.org/x/net/context"
 	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
-	"golang.org/x/oauth2/jwt"
 	pbsb "google.golang.org/api/pubsub/v1"
 )
 
+// Scopes lists the oauth2 scopes required by PbsbManager
+var Scopes = []string{
+	pbsb.CloudPlatformScope,
+	pbsb.PubsubScope,
+}
+
 // BuildPbsbService builds the singlton service for PbsbManager
 func BuildPbsbService(serviceEmail string, key []byte) (*pbsb.Service, error) {
-	conf := &jwt.Config{
-		Email:      serviceEmail,
-		PrivateKey: key,
-		Scopes: []string{
-			pbsb.CloudPlatformScope,
-			pbsb.PubsubScope,
-		},
-		TokenURL: google.JWTTokenURL,
+	ts, err := auth.PemKey(serviceEmail, key).TokenSource(oauth2.NoContext, Scopes...)
+	if err != nil {
+		return nil, err
 	}
 
-	if service, err := pbsb.New(conf.Client(oauth2.NoContext)); err != nil {
+	return BuildPbsbServiceWithClient(oauth2.NewClient(oauth2.NoContext, ts))
+}
+
+// BuildPbsbServiceWithClient builds the service for PbsbManager upon an authorized http client
+func BuildPbsbServiceWithClient(client *http.Client) (*pbsb.Service, error) {
+	if service, err := pbsb.New(client); err != nil {
 		return nil, err
 	} else {
 		return service, nil
 	}
 }
 
+//go:generate mockery -name=Topics
+
+// Topics is the method set of PbsbManager, it is for consumers to mock PbsbManager.
+type Topics interface {
+	Setup()
+	ListTopics(projectId string) ([]*pbsb.Topic, error)
+	ListTopicsContext(ctx context.Context, projectId string) ([]*pbsb.Topic, error)
+}
+
+var _ Topics = (*PbsbManager)(nil)
+
 // PbsbManager communicates with google cloud pub/sub
 type PbsbManager struct {
 	Service       *pbsb.Service `inject:""`
@@ -41,12 +60,21 @@ func (manager *PbsbManager) Setup() {
 	manager.topicsService = pbsb.NewProjectsTopicsService(manager.Service)
 }
 
-func (manager *PbsbManager) ListTopics(projectId string) {
-	response, err := manager.topicsService.List(projectId).Do()
+// ListTopics lists all topics of the project, which is in the form "projects/{project}"
+func (manager *PbsbManager) ListTopics(projectId string) ([]*pbsb.Topic, error) {
+	return manager.ListTopicsContext(context.Background(), projectId)
+}
+
+// ListTopicsContext is ListTopics with the context of the requests
+func (manager *PbsbManager) ListTopicsContext(ctx context.Context, projectId string) ([]*pbsb.Topic, error) {
+	topics := []*pbsb.Topic{}
+	err := manager.topicsService.List(projectId).Pages(ctx, func(page *pbsb.ListTopicsResponse) error {
+		topics = append(topics, page.Topics...)
+		return nil
+	})
 	if err != nil {
-		log.Printf("err: %+v", err)
-		return
+		return nil, gerror.New("pubsub", "ListTopics", err)
 	}
 
-	log.Printf("topics: %+v", response.Topics[0].Name)
+	return topics, nil
 }
@@ -54,7 +54,8 @@ func (suite *PbsbManagerTestSuite) SetupSuite() {
 }
 
 func (suite *PbsbManagerTestSuite) Test01_ListTopics() {
-	testedPbsbManager.ListTopics("projects/livehouse-test")
+	_, err := testedPbsbManager.ListTopics("projects/livehouse-test")
+	suite.Nil(err)
 }
 
 func (suite *PbsbManagerTestSuite) TearDownSuite() {
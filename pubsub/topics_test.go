@@ -0,0 +1,20 @@
+package pubsub_test
+
+import (
+	"testing"
+
+	"github.com/browny/gogoo/gogootest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListTopics(t *testing.T) {
+	server, g := gogootest.NewGoGoo()
+	defer server.Close()
+
+	server.AddTopic("topic-test")
+
+	topics, err := g.ListTopics("projects/" + gogootest.ProjectId)
+	assert.Nil(t, err)
+	assert.Equal(t, "projects/gogootest/topics/topic-test", topics[0].Name)
+}
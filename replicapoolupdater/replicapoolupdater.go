@@ -2,36 +2,61 @@
 package replicapoolupdater
 
 import (
-	"fmt"
+	"net/http"
+
+	"github.com/browny/gogoo/auth"
+	"github.com/browny/gogoo/gerror"
 
 	log "github.com/cihub/seelog"
+	"golang.org/x/net/context"
 	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
-	"golang.org/x/oauth2/jwt"
 
 	rpu "google.golang.org/api/replicapoolupdater/v1beta1"
 )
 
-var rollingUpdateError = fmt.Errorf("RollingUpdate Error")
+// rollingUpdateError wraps the cause into *gerror.Error
+func rollingUpdateError(operation string, err error) error {
+	return gerror.New("replicapoolupdater", operation, err)
+}
+
+// Scopes lists the oauth2 scopes required by RpuManager
+var Scopes = []string{
+	rpu.ReplicapoolScope,
+}
 
 // BuildRpuService builds the singlton service for RpuService
 func BuildRpuService(serviceEmail string, key []byte) (*rpu.Service, error) {
-	conf := &jwt.Config{
-		Email:      serviceEmail,
-		PrivateKey: key,
-		Scopes: []string{
-			rpu.ReplicapoolScope,
-		},
-		TokenURL: google.JWTTokenURL,
+	ts, err := auth.PemKey(serviceEmail, key).TokenSource(oauth2.NoContext, Scopes...)
+	if err != nil {
+		return nil, err
 	}
 
-	if service, err := rpu.New(conf.Client(oauth2.NoContext)); err != nil {
+	return BuildRpuServiceWithClient(oauth2.NewClient(oauth2.NoContext, ts))
+}
+
+// BuildRpuServiceWithClient builds the service for RpuService upon an authorized http client
+func BuildRpuServiceWithClient(client *http.Client) (*rpu.Service, error) {
+	if service, err := rpu.New(client); err != nil {
 		return nil, err
 	} else {
 		return service, nil
 	}
 }
 
+//go:generate mockery -name=Updater
+
+// Updater is the method set of RpuManager, it is for consumers to mock RpuManager.
+type Updater interface {
+	Insert(projectId, zone string, rollingUpdate *rpu.RollingUpdate) (*rpu.Operation, error)
+	InsertContext(ctx context.Context, projectId, zone string, rollingUpdate *rpu.RollingUpdate) (*rpu.Operation, error)
+	List(projectId, zone string) (*rpu.RollingUpdateList, error)
+	ListContext(ctx context.Context, projectId, zone string) (*rpu.RollingUpdateList, error)
+	Rollback(projectId, zone, rollingUpdateId string) (*rpu.Operation, error)
+	RollbackContext(ctx context.Context, projectId, zone, rollingUpdateId string) (*rpu.Operation, error)
+}
+
+var _ Updater = (*RpuManager)(nil)
+
 // RpuManager
 //
 // https://godoc.org/google.golang.org/api/replicapoolupdater/v1beta1
@@ -43,15 +68,22 @@ type RpuManager struct {
 //
 // https://godoc.org/google.golang.org/api/replicapoolupdater/v1beta1#RollingUpdatesService.Insert
 func (manager *RpuManager) Insert(projectId, zone string, rollingUpdate *rpu.RollingUpdate) (*rpu.Operation, error) {
+	return manager.InsertContext(context.Background(), projectId, zone, rollingUpdate)
+}
+
+// InsertContext is Insert with the context of the request
+func (manager *RpuManager) InsertContext(
+	ctx context.Context, projectId, zone string, rollingUpdate *rpu.RollingUpdate) (*rpu.Operation, error) {
+
 	log.Tracef("Insert: projectId[%s], zone[%s]", projectId, zone)
 
 	rollingUpdateService := rpu.NewRollingUpdatesService(manager.Service)
 
-	op, err := rollingUpdateService.Insert(projectId, zone, rollingUpdate).Do()
+	op, err := rollingUpdateService.Insert(projectId, zone, rollingUpdate).Context(ctx).Do()
 	if err != nil {
 		log.Warnf("Error: %s", err.Error())
 
-		return nil, rollingUpdateError
+		return nil, rollingUpdateError("Insert", err)
 	}
 
 	return op, nil
@@ -61,15 +93,20 @@ func (manager *RpuManager) Insert(projectId, zone string, rollingUpdate *rpu.Rol
 //
 // https://godoc.org/google.golang.org/api/replicapoolupdater/v1beta1#RollingUpdatesService.List
 func (manager *RpuManager) List(projectId, zone string) (*rpu.RollingUpdateList, error) {
+	return manager.ListContext(context.Background(), projectId, zone)
+}
+
+// ListContext is List with the context of the request
+func (manager *RpuManager) ListContext(ctx context.Context, projectId, zone string) (*rpu.RollingUpdateList, error) {
 	log.Tracef("List: projectId[%s], zone[%s]", projectId, zone)
 
 	rollingUpdateService := rpu.NewRollingUpdatesService(manager.Service)
 
-	list, err := rollingUpdateService.List(projectId, zone).Do()
+	list, err := rollingUpdateService.List(projectId, zone).Context(ctx).Do()
 	if err != nil {
 		log.Warnf("Error: %s", err.Error())
 
-		return nil, rollingUpdateError
+		return nil, rollingUpdateError("List", err)
 	}
 
 	return list, nil
@@ -79,15 +116,22 @@ func (manager *RpuManager) List(projectId, zone string) (*rpu.RollingUpdateList,
 //
 // https://godoc.org/google.golang.org/api/replicapoolupdater/v1beta1#RollingUpdatesService.Rollback
 func (manager *RpuManager) Rollback(projectId, zone, rollingUpdateId string) (*rpu.Operation, error) {
+	return manager.RollbackContext(context.Background(), projectId, zone, rollingUpdateId)
+}
+
+// RollbackContext is Rollback with the context of the request
+func (manager *RpuManager) RollbackContext(
+	ctx context.Context, projectId, zone, rollingUpdateId string) (*rpu.Operation, error) {
+
 	log.Tracef("Rollback: projectId[%s], zone[%s], rollingUpdateId[%s]", projectId, zone, rollingUpdateId)
 
 	rollingUpdateService := rpu.NewRollingUpdatesService(manager.Service)
 
-	op, err := rollingUpdateService.Rollback(projectId, zone, rollingUpdateId).Do()
+	op, err := rollingUpdateService.Rollback(projectId, zone, rollingUpdateId).Context(ctx).Do()
 	if err != nil {
 		log.Warnf("Error: %s", err.Error())
 
-		return nil, rollingUpdateError
+		return nil, rollingUpdateError("Rollback", err)
 	}
 
 	return op, nil
@@ -0,0 +1,31 @@
+package replicapoolupdater_test
+
+import (
+	"testing"
+
+	"github.com/browny/gogoo/gogootest"
+
+	"github.com/stretchr/testify/assert"
+	rpu "google.golang.org/api/replicapoolupdater/v1beta1"
+)
+
+func TestRollingUpdates(t *testing.T) {
+	server, g := gogootest.NewGoGoo()
+	defer server.Close()
+
+	_, err := g.Updater.Insert(gogootest.ProjectId, "asia-east1-b", &rpu.RollingUpdate{
+		InstanceGroupManager: "group-test",
+		InstanceTemplate:     "template-test",
+	})
+	assert.Nil(t, err)
+
+	list, err := g.Updater.List(gogootest.ProjectId, "asia-east1-b")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(list.Items))
+
+	_, err = g.Updater.Rollback(gogootest.ProjectId, "asia-east1-b", list.Items[0].Id)
+	assert.Nil(t, err)
+
+	list, _ = g.Updater.List(gogootest.ProjectId, "asia-east1-b")
+	assert.Equal(t, "ROLLED_BACK", list.Items[0].Status)
+}
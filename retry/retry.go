@@ -0,0 +1,107 @@
+// Package retry retries the transient failures of google apis with jittered exponential backoff.
+//
+// GoGoo wires the policy of AppContext into the http client shared by all managers,
+// and the policy of a single call can be overridden by its context
+//
+//	ctx := retry.WithPolicy(context.Background(), retry.NoRetry)
+//	vm, err := g.GetVmContext(ctx, projectId, zone, vmName)
+package retry
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/browny/gogoo/gerror"
+
+	"golang.org/x/net/context"
+)
+
+// Policy tells how a failed call is retried
+type Policy struct {
+	// MaxAttempts is the number of calls including the first one, there is no retry if it is less than 2
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between two attempts
+	MaxBackoff time.Duration
+	// Multiplier grows the backoff after each retry, 2 is used if it is less than 1
+	Multiplier float64
+	// Jitter randomizes the backoff by the fraction, e.g. 0.2 waits 80% ~ 120% of the backoff
+	Jitter float64
+	// Retryable classifies the error to retry, gerror.IsRetryable is used if it is nil
+	Retryable func(error) bool
+	// RetryNonIdempotent lets Transport retry any failed POST and PATCH requests too. The failed attempt
+	// may have been applied, e.g. the retried insert may fail with 409 "alreadyExists"
+	RetryNonIdempotent bool
+}
+
+// DefaultPolicy retries the rate limited, 5xx responses and network timeouts
+var DefaultPolicy = Policy{
+	MaxAttempts:    5,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     30 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// NoRetry calls only once
+var NoRetry = Policy{MaxAttempts: 1}
+
+type policyKey struct{}
+
+// WithPolicy attaches the policy to ctx, it overrides the policy of the manager for the calls with ctx
+func WithPolicy(ctx context.Context, policy Policy) context.Context {
+	return context.WithValue(ctx, policyKey{}, policy)
+}
+
+type idempotentKey struct{}
+
+// WithIdempotent marks the calls with ctx safe to repeat, so Transport retries them whatever
+// their method, e.g. a PATCH sending the whole settings
+func WithIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+func isIdempotent(ctx context.Context) bool {
+	idempotent, _ := ctx.Value(idempotentKey{}).(bool)
+	return idempotent
+}
+
+// FromContext gets the policy attached by WithPolicy
+func FromContext(ctx context.Context) (Policy, bool) {
+	policy, ok := ctx.Value(policyKey{}).(Policy)
+	return policy, ok
+}
+
+// Backoff returns the jittered wait after the attempt, which starts from 1
+func (p Policy) Backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+
+	backoff := float64(p.InitialBackoff)
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || backoff < float64(p.MaxBackoff)); i++ {
+		backoff *= multiplier
+	}
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		backoff *= 1 - p.Jitter + 2*p.Jitter*rand.Float64()
+	}
+
+	return time.Duration(backoff)
+}
+
+// shouldRetry tells whether to retry after the attempt fails by err
+func (p Policy) shouldRetry(attempt int, err error) bool {
+	if err == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+
+	return gerror.IsRetryable(err)
+}
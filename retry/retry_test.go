@@ -0,0 +1,146 @@
+package retry_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/browny/gogoo/gerror"
+	"github.com/browny/gogoo/retry"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/api/googleapi"
+)
+
+var testedPolicy = retry.Policy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     5 * time.Millisecond,
+}
+
+func TestTransportPolicy(t *testing.T) {
+	attempts := 0
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(status)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &retry.Transport{Policy: &testedPolicy}}
+	get := func(ctx context.Context) {
+		attempts = 0
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		if resp, err := client.Do(req.WithContext(ctx)); err == nil {
+			resp.Body.Close()
+		}
+	}
+
+	// Attempts are used up
+	get(context.Background())
+	assert.Equal(t, 3, attempts)
+
+	// The policy of the context overrides the one of the transport
+	get(retry.WithPolicy(context.Background(), retry.NoRetry))
+	assert.Equal(t, 1, attempts)
+
+	// Not retryable
+	status = http.StatusNotFound
+	get(context.Background())
+	assert.Equal(t, 1, attempts)
+
+	// Classified by the custom classifier
+	custom := testedPolicy
+	custom.Retryable = gerror.IsNotFound
+	get(retry.WithPolicy(context.Background(), custom))
+	assert.Equal(t, 3, attempts)
+
+	// Cancellation stops retrying
+	status = http.StatusServiceUnavailable
+	ctx, cancel := context.WithCancel(context.Background())
+	slow := retry.Policy{MaxAttempts: 5, InitialBackoff: time.Hour}
+	time.AfterFunc(10*time.Millisecond, cancel)
+	get(retry.WithPolicy(ctx, slow))
+	assert.Equal(t, 1, attempts)
+}
+
+func TestBackoff(t *testing.T) {
+	policy := retry.Policy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Multiplier: 2}
+	assert.Equal(t, time.Second, policy.Backoff(1))
+	assert.Equal(t, 2*time.Second, policy.Backoff(2))
+	assert.Equal(t, 4*time.Second, policy.Backoff(3))
+	assert.Equal(t, 5*time.Second, policy.Backoff(4))
+	assert.Equal(t, 5*time.Second, policy.Backoff(100))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		backoff := policy.Backoff(1)
+		assert.True(t, backoff >= 500*time.Millisecond && backoff <= 1500*time.Millisecond)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	bodies := []string{}
+	status, failure := http.StatusTooManyRequests, `{"error": {"code": 429, "errors": [{"reason": "rateLimitExceeded"}]}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) < 3 {
+			w.WriteHeader(status)
+			w.Write([]byte(failure))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &retry.Transport{Policy: &testedPolicy}}
+
+	// The rate limited POST is rejected before it is applied, so it is retried
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"name": "instance-test"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{`{"name": "instance-test"}`, `{"name": "instance-test"}`, `{"name": "instance-test"}`}, bodies)
+
+	// The POST failed by 5xx may have been applied, so it is not retried by default
+	status, failure = http.StatusServiceUnavailable, `{"error": {"code": 503, "errors": [{"reason": "backendError"}]}}`
+	bodies = []string{}
+	resp, err = client.Post(server.URL, "application/json", strings.NewReader(`{"name": "instance-test"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, 1, len(bodies))
+
+	// The POST is retried with the requestId, the mark of the context or the opt-in of the policy
+	bodies = []string{}
+	resp, err = client.Post(server.URL+"?requestId=1", "application/json", strings.NewReader(`{}`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	bodies = []string{}
+	req, _ := http.NewRequest("PATCH", server.URL, strings.NewReader(`{}`))
+	resp, err = client.Do(req.WithContext(retry.WithIdempotent(context.Background())))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	bodies = []string{}
+	optIn := testedPolicy
+	optIn.RetryNonIdempotent = true
+	req, _ = http.NewRequest("POST", server.URL, strings.NewReader(`{}`))
+	resp, err = client.Do(req.WithContext(retry.WithPolicy(context.Background(), optIn)))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// The body of the last failed response is kept for the caller
+	bodies = []string{}
+	req, _ = http.NewRequest("GET", server.URL, nil)
+	resp, err = client.Do(req.WithContext(retry.WithPolicy(context.Background(), retry.NoRetry)))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.True(t, gerror.IsRetryable(googleapi.CheckResponse(resp)))
+	assert.Equal(t, 1, len(bodies))
+}
@@ -0,0 +1,136 @@
+package retry
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/browny/gogoo/gerror"
+
+	log "github.com/cihub/seelog"
+	"google.golang.org/api/googleapi"
+)
+
+// Transport retries the requests by the policy of their context, or Policy if they have none.
+// The idempotent requests (GET, HEAD, PUT, DELETE, OPTIONS) are retried. The other requests are retried
+// only if they are rate limited, which the server rejects before applying them, if they carry the
+// `requestId` which compute engine deduplicates, if their context is marked by WithIdempotent, or
+// if the policy opts in by RetryNonIdempotent. The request with a body which can not be replayed,
+// i.e. without GetBody, is sent only once.
+type Transport struct {
+	// Base sends the requests, http.DefaultTransport is used if it is nil
+	Base http.RoundTripper
+	// Policy is the default policy, DefaultPolicy is used if it is nil
+	Policy *Policy
+}
+
+// RoundTrip sends the request and retries the retryable failures
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	policy := DefaultPolicy
+	if override, ok := FromContext(req.Context()); ok {
+		policy = override
+	} else if t.Policy != nil {
+		policy = *t.Policy
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := t.base().RoundTrip(req)
+
+		// The error of the response is classified as the managers receive it
+		failure := err
+		if err == nil && resp.StatusCode >= http.StatusBadRequest {
+			failure = checkResponse(resp)
+		}
+		if !policy.shouldRetry(attempt, failure) || !replayable(req, policy, failure) {
+			return resp, err
+		}
+
+		retried, bodyErr := rewind(req)
+		if bodyErr != nil {
+			return resp, err
+		}
+
+		wait := policy.Backoff(attempt)
+		log.Debugf("Retry after %s: %s %s, attempt[%d], err[%s]", wait, req.Method, req.URL, attempt, failure)
+		select {
+		case <-req.Context().Done():
+			return resp, err
+		case <-time.After(wait):
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+		req = retried
+	}
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+
+	return http.DefaultTransport
+}
+
+// replayable tells whether the request failed by failure can be sent again by the policy
+func replayable(req *http.Request, policy Policy, failure error) bool {
+	if req.Body != nil && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+
+	return rateLimited(failure) || req.URL.Query().Get("requestId") != "" ||
+		isIdempotent(req.Context()) || policy.RetryNonIdempotent
+}
+
+// rateLimited tells whether the response is 429 or has the reason of rate limits
+func rateLimited(err error) bool {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if apiErr.Code == http.StatusTooManyRequests {
+		return true
+	}
+	for _, item := range apiErr.Errors {
+		if item.Reason == gerror.ReasonRateLimitExceeded || item.Reason == gerror.ReasonUserRateLimit {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkResponse builds the error of the failed response and keeps its body readable
+func checkResponse(resp *http.Response) error {
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	return googleapi.CheckResponse(&http.Response{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       ioutil.NopCloser(bytes.NewReader(body)),
+	})
+}
+
+// rewind copies the request with a fresh body for the next attempt
+func rewind(req *http.Request) (*http.Request, error) {
+	retried := req.WithContext(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retried.Body = body
+	}
+
+	return retried, nil
+}
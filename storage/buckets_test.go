@@ -0,0 +1,28 @@
+package storage_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/browny/gogoo/gerror"
+	"github.com/browny/gogoo/gogootest"
+
+	"github.com/stretchr/testify/assert"
+	storage "google.golang.org/api/storage/v1"
+)
+
+func TestListBuckets(t *testing.T) {
+	server, g := gogootest.NewGoGoo()
+	defer server.Close()
+
+	server.AddBucket(&storage.Bucket{Name: "bucket-test"})
+
+	buckets, err := g.ListBuckets(gogootest.ProjectId)
+	assert.Nil(t, err)
+	assert.Equal(t, "bucket-test", buckets[0].Name)
+
+	server.FailNextRequests(1, http.StatusForbidden, "forbidden")
+	_, err = g.ListBuckets(gogootest.ProjectId)
+	assert.NotNil(t, err)
+	assert.Equal(t, "storage", err.(*gerror.Error).Service)
+}
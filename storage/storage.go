@@ -2,27 +2,35 @@
 package storage
 
 import (
-	"log"
+	"net/http"
 
+	"github.com/browny/gogoo/auth"
+	"github.com/browny/gogoo/gerror"
+
+	"golang.org/x/net/context"
 	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
-	"golang.org/x/oauth2/jwt"
 	storage "google.golang.org/api/storage/v1"
 )
 
+// Scopes lists the oauth2 scopes required by StorageManager
+var Scopes = []string{
+	storage.DevstorageFullControlScope,
+	storage.DevstorageReadWriteScope,
+}
+
 // BuildStorageService builds the singlton service for Storage
 func BuildStorageService(serviceEmail string, key []byte) (*storage.Service, error) {
-	conf := &jwt.Config{
-		Email:      serviceEmail,
-		PrivateKey: key,
-		Scopes: []string{
-			storage.DevstorageFullControlScope,
-			storage.DevstorageReadWriteScope,
-		},
-		TokenURL: google.JWTTokenURL,
+	ts, err := auth.PemKey(serviceEmail, key).TokenSource(oauth2.NoContext, Scopes...)
+	if err != nil {
+		return nil, err
 	}
 
-	service, err := storage.New(conf.Client(oauth2.NoContext))
+	return BuildStorageServiceWithClient(oauth2.NewClient(oauth2.NoContext, ts))
+}
+
+// BuildStorageServiceWithClient builds the service for Storage upon an authorized http client
+func BuildStorageServiceWithClient(client *http.Client) (*storage.Service, error) {
+	service, err := storage.New(client)
 	if err != nil {
 		return nil, err
 	}
@@ -30,6 +38,18 @@ func BuildStorageService(serviceEmail string, key []byte) (*storage.Service, err
 	return service, nil
 }
 
+//go:generate mockery -name=Buckets
+
+// Buckets is the method set of StorageManager, it is for consumers to mock StorageManager.
+type Buckets interface {
+	Setup()
+	ListBuckets(projectID string) ([]*storage.Bucket, error)
+	ListBucketsContext(ctx context.Context, projectID string) ([]*storage.Bucket, error)
+}
+
+var _ Buckets = (*StorageManager)(nil)
+
+// StorageManager communicates with google storage
 type StorageManager struct {
 	*storage.Service `inject:""`
 	bucketService    *storage.BucketsService
@@ -39,12 +59,21 @@ func (manager *StorageManager) Setup() {
 	manager.bucketService = storage.NewBucketsService(manager.Service)
 }
 
-func (manager *StorageManager) ListBuckets(projectID string) {
-	buckets, err := manager.bucketService.List(projectID).Do()
+// ListBuckets lists all buckets of the project
+func (manager *StorageManager) ListBuckets(projectID string) ([]*storage.Bucket, error) {
+	return manager.ListBucketsContext(context.Background(), projectID)
+}
+
+// ListBucketsContext is ListBuckets with the context of the requests
+func (manager *StorageManager) ListBucketsContext(ctx context.Context, projectID string) ([]*storage.Bucket, error) {
+	buckets := []*storage.Bucket{}
+	err := manager.bucketService.List(projectID).Pages(ctx, func(page *storage.Buckets) error {
+		buckets = append(buckets, page.Items...)
+		return nil
+	})
 	if err != nil {
-		log.Printf("err: %+v", err)
-		return
+		return nil, gerror.New("storage", "ListBuckets", err)
 	}
 
-	log.Printf("buckets: %+v", buckets.Items[0].Name)
+	return buckets, nil
 }
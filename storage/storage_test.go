@@ -54,7 +54,8 @@ func (suite *StorageManagerTestSuite) SetupSuite() {
 }
 
 func (suite *StorageManagerTestSuite) Test01_ListBuckets() {
-	testedStorageManager.ListBuckets("livehouse-test")
+	_, err := testedStorageManager.ListBuckets("livehouse-test")
+	suite.Nil(err)
 }
 
 func (suite *StorageManagerTestSuite) TearDownSuite() {